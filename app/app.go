@@ -2,6 +2,7 @@ package app
 
 import (
 	"claude-squad/config"
+	"claude-squad/daemon"
 	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
@@ -23,8 +24,17 @@ const GlobalInstanceLimit = 10
 
 // Run is the main entrypoint into the application.
 func Run(ctx context.Context, program string, autoYes bool) error {
+	session.SetAuditSource("ui")
+	h := newHome(ctx, program, autoYes)
+	defer h.disconnect()
+	// Hand the project back, e.g. to the daemon.
+	defer func() {
+		if err := h.lease.Release(); err != nil {
+			log.WarningLog.Printf("failed to release the lease of the project: %v", err)
+		}
+	}()
 	p := tea.NewProgram(
-		newHome(ctx, program, autoYes),
+		h,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(), // Mouse scroll
 	)
@@ -53,6 +63,16 @@ type home struct {
 
 	program string
 	autoYes bool
+	// lease is the ownership of the project's instances. Only the owner, usually the daemon, accepts
+	// prompts, restarts dead sessions and pauses idle instances. The UI takes the project over when
+	// nobody else owns it.
+	lease *session.Lease
+	// controller sends operations on instances to the daemon if it runs, so that it and every other
+	// frontend sees them right away.
+	controller *daemon.Controller
+	// events is the connection on which the daemon streams changes of the instances, nil if the UI
+	// works without the daemon.
+	events *daemon.Client
 
 	// instanceManager handles project-specific instance management
 	instanceManager *session.InstanceManager
@@ -60,6 +80,10 @@ type home struct {
 	projectManager *session.ProjectInstanceManager
 	// appConfig stores persistent application configuration
 	appConfig *config.Config
+	// approvals decides which prompts of instances with AutoYes enabled are accepted
+	approvals *config.ApprovalMatcher
+	// supervisor notices instances whose session died and restarts them if the UI owns the project
+	supervisor *session.Supervisor
 	// appState stores persistent application state like seen help screens
 	appState config.AppState
 
@@ -67,9 +91,9 @@ type home struct {
 
 	// state is the current discrete state of the application
 	state state
-	// newInstanceFinalizer is called when the state is stateNew and then you press enter.
-	// It registers the new instance in the list after the instance has been started.
-	newInstanceFinalizer func()
+	// newInstance is the instance being named when the state is stateNew. Once you press enter, it is
+	// replaced by the created instance.
+	newInstance *session.Instance
 
 	// promptAfterName tracks if we should enter prompt mode after naming
 	promptAfterName bool
@@ -144,19 +168,40 @@ func newHome(ctx context.Context, program string, autoYes bool) *home {
 		instanceManager: instanceManager,
 		projectManager:  projectManager,
 		appConfig:       appConfig,
+		approvals:       appConfig.ApprovalMatcher(),
 		program:         program,
 		autoYes:         autoYes,
+		lease:           projectManager.NewLease("ui"),
 		state:           stateDefault,
 		appState:        appState,
 	}
 	h.list = ui.NewList(&h.spinner, autoYes)
-
-	// Load saved instances for current project
-	log.InfoLog.Printf("[APP] Loading saved instances for current project...")
-	instances, err := projectManager.GetAllInstances()
-	if err != nil {
-		fmt.Printf("Failed to load instances: %v\n", err)
-		os.Exit(1)
+	// Dead sessions are only reported unless the UI owns the project.
+	h.supervisor = session.NewSupervisor(func(projectID string) *config.RestartPolicy {
+		if !h.lease.Held() {
+			return nil
+		}
+		return instanceManager.RestartPolicy(projectID)
+	}, false)
+
+	// With the daemon running, the UI is one of its frontends: it shows the instances the daemon
+	// reports and asks it to change them. Otherwise it manages the instances itself.
+	h.controller = daemon.NewController(projectManager)
+	var instances []*session.Instance
+	if h.controller.Connected() {
+		if instances, err = h.subscribe(); err != nil {
+			log.WarningLog.Printf("failed to subscribe to the daemon, working without it: %v", err)
+			h.controller.Disconnect()
+		}
+	}
+	if !h.controller.Connected() {
+		// Load saved instances for current project
+		log.InfoLog.Printf("[APP] Loading saved instances for current project...")
+		instances, err = projectManager.GetAllInstances()
+		if err != nil {
+			fmt.Printf("Failed to load instances: %v\n", err)
+			os.Exit(1)
+		}
 	}
 	log.InfoLog.Printf("[APP] Loaded %d instances for project", len(instances))
 
@@ -165,14 +210,51 @@ func newHome(ctx context.Context, program string, autoYes bool) *home {
 		log.InfoLog.Printf("[APP] Adding instance %d: %s (DisplayName: %s)", i, instance.Title, instance.DisplayName)
 		// Call the finalizer immediately.
 		h.list.AddInstance(instance)()
-		if autoYes {
+		if autoYes && !instance.AutoYes {
+			// Stored so that the daemon accepts the prompts of the instance as well.
 			instance.AutoYes = true
+			if err := projectManager.UpdateInstance(instance); err != nil {
+				log.ErrorLog.Printf("Failed to save instance %s: %v", instance.Title, err)
+			}
 		}
 	}
 
 	return h
 }
 
+// subscribe opens the connection on which the daemon streams changes of the instances and returns
+// the instances of the project.
+func (m *home) subscribe() ([]*session.Instance, error) {
+	events, err := daemon.Dial()
+	if err != nil {
+		return nil, err
+	}
+	data, err := events.Subscribe()
+	if err != nil {
+		events.Close()
+		return nil, err
+	}
+	m.events = events
+
+	var instances []*session.Instance
+	for _, instanceData := range data {
+		if instanceData.ProjectID == m.projectManager.GetProjectID() {
+			// The daemon watches the session, the UI only attaches to it when asked to.
+			instances = append(instances, session.FromInstanceDataDetached(instanceData))
+		}
+	}
+	return instances, nil
+}
+
+// disconnect closes the connections to the daemon, if any. Further operations are done by the UI.
+func (m *home) disconnect() {
+	m.controller.Disconnect()
+	if m.events != nil {
+		m.events.Close()
+		m.events = nil
+	}
+}
+
 // updateHandleWindowSizeEvent sets the sizes of the components.
 // The components will try to render inside their bounds.
 func (m *home) updateHandleWindowSizeEvent(msg tea.WindowSizeMsg) {
@@ -205,14 +287,18 @@ func (m *home) updateHandleWindowSizeEvent(msg tea.WindowSizeMsg) {
 func (m *home) Init() tea.Cmd {
 	// Upon starting, we want to start the spinner. Whenever we get a spinner.TickMsg, we
 	// update the spinner, which sends a new spinner.TickMsg. I think this lasts forever lol.
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.spinner.Tick,
 		func() tea.Msg {
 			time.Sleep(100 * time.Millisecond)
 			return previewTickMsg{}
 		},
 		tickUpdateMetadataCmd,
-	)
+	}
+	if m.events != nil {
+		cmds = append(cmds, m.nextEventCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -232,25 +318,47 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.menu.ClearKeydown()
 		return m, nil
 	case tickUpdateMetadataMessage:
+		// The daemon reports the status of the instances if it runs.
+		local := !m.controller.Connected()
+		owner := local && m.ownsProject()
 		for _, instance := range m.list.GetInstances() {
 			if !instance.Started() || instance.Paused() {
 				continue
 			}
-			updated, prompt := instance.HasUpdated()
-			if updated {
-				instance.SetStatus(session.Running)
-			} else {
-				if prompt {
-					instance.TapEnter()
-				} else {
-					instance.SetStatus(session.Ready)
+			if local && m.supervisor.Check(instance) && owner {
+				if err := m.projectManager.SaveStatus(instance); err != nil {
+					log.ErrorLog.Printf("failed to save the status of %s: %v", instance.Title, err)
 				}
 			}
+			if instance.Status == session.Error {
+				continue
+			}
+			if owner {
+				instance.RefreshStatus(m.approvals)
+			} else if local {
+				instance.UpdateStatus(m.approvals)
+			}
 			if err := instance.UpdateDiffStats(); err != nil {
 				log.WarningLog.Printf("could not update diff stats: %v", err)
 			}
+			if owner {
+				if paused, err := m.projectManager.PauseIfIdle(instance, m.appConfig.IdlePause.Duration()); err != nil {
+					log.WarningLog.Printf("failed to pause idle instance %s: %v", instance.Title, err)
+				} else if paused {
+					log.InfoLog.Printf("paused instance %s, it was idle for %d minutes", instance.Title, m.appConfig.IdlePause.After)
+				}
+			}
 		}
 		return m, tickUpdateMetadataCmd
+	case daemonEventMsg:
+		m.handleDaemonEvent(msg.event)
+		return m, tea.Batch(m.nextEventCmd(), m.instanceChanged())
+	case daemonGoneMsg:
+		// Take the project over like without the daemon. The instances are kept, the UI watches them
+		// from now on.
+		log.WarningLog.Printf("lost the connection to the daemon, working without it: %v", msg.err)
+		m.disconnect()
+		return m, nil
 	case tea.MouseMsg:
 		// Handle mouse wheel events for scrolling the diff/preview pane
 		if msg.Action == tea.MouseActionPress {
@@ -282,72 +390,47 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.instanceChanged()
 	case translationCompleteMsg:
 		// Handle translation completion
-		log.InfoLog.Printf("[PERF] Translation completed for instance #%d, translated to '%s'", msg.instanceIdx, msg.translatedID)
-		instances := m.list.GetInstances()
-		if msg.instanceIdx >= 0 && msg.instanceIdx < len(instances) {
-			instance := instances[msg.instanceIdx]
-
-			// Set the translated title
-			if err := instance.SetTitle(msg.translatedID); err != nil {
-				return m, m.handleError(err)
-			}
-
-			log.InfoLog.Printf("[PERF] Starting async instance startup for '%s'", msg.translatedID)
-			// Start the instance asynchronously (keep Translating status to show spinner)
-			return m, m.startInstanceCmd(msg.instanceIdx, true)
+		log.InfoLog.Printf("[PERF] Translation completed for instance '%s', translated to '%s'", msg.instance.DisplayName, msg.translatedID)
+		// Set the translated title
+		if err := msg.instance.SetTitle(msg.translatedID); err != nil {
+			return m, m.handleError(err)
 		}
-		return m, nil
+
+		log.InfoLog.Printf("[PERF] Starting async instance startup for '%s'", msg.translatedID)
+		// Create the instance asynchronously (keep Translating status to show spinner)
+		return m, m.createInstanceCmd(msg.instance)
 	case instanceStartCompleteMsg:
 		// Handle instance startup completion
-		log.InfoLog.Printf("[PERF] Instance startup completed for instance #%d (error: %v)", msg.instanceIdx, msg.err)
-		instances := m.list.GetInstances()
-		if msg.instanceIdx >= 0 && msg.instanceIdx < len(instances) {
-			instance := instances[msg.instanceIdx]
-
-			// Check if startup failed
-			if msg.err != nil {
-				m.list.Kill()
-				m.state = stateDefault
-				return m, m.handleError(msg.err)
-			}
-
-			// Save after adding new instance
-			instances := m.list.GetInstances()
-			for _, instance := range instances {
-				if err := m.projectManager.SaveInstance(instance); err != nil {
-					log.ErrorLog.Printf("Failed to save instance %s: %v", instance.Title, err)
-				}
-			}
-
-			// Instance added successfully, call the finalizer
-			m.newInstanceFinalizer()
-			if m.autoYes {
-				instance.AutoYes = true
-			}
-
-			// Save after adding new instance
-			instances = m.list.GetInstances()
-			for _, instance := range instances {
-				if err := m.projectManager.SaveInstance(instance); err != nil {
-					log.ErrorLog.Printf("Failed to save instance %s: %v", instance.Title, err)
-				}
-			}
+		log.InfoLog.Printf("[PERF] Instance startup completed for instance '%s' (error: %v)", msg.instance.Title, msg.err)
+		// The created instance takes the place of the one which was named.
+		m.list.Remove(msg.instance)
+		m.newInstance = nil
 
+		// Check if startup failed
+		if msg.err != nil {
 			m.state = stateDefault
-			if m.promptAfterName {
-				m.state = statePrompt
-				m.menu.SetState(ui.StatePrompt)
-				// Initialize the text input overlay
-				m.textInputOverlay = overlay.NewTextInputOverlay("Enter prompt", "")
-				m.promptAfterName = false
-			} else {
-				m.menu.SetState(ui.StateDefault)
-				m.showHelpScreen(helpStart(instance), nil)
-			}
+			m.promptAfterName = false
+			m.menu.SetState(ui.StateDefault)
+			return m, m.handleError(msg.err)
+		}
 
-			return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+		instance := msg.created
+		m.list.AddInstance(instance)()
+		m.list.SetSelectedInstance(m.list.NumInstances() - 1)
+
+		m.state = stateDefault
+		if m.promptAfterName {
+			m.state = statePrompt
+			m.menu.SetState(ui.StatePrompt)
+			// Initialize the text input overlay
+			m.textInputOverlay = overlay.NewTextInputOverlay("Enter prompt", "")
+			m.promptAfterName = false
+		} else {
+			m.menu.SetState(ui.StateDefault)
+			m.showHelpScreen(helpStart(instance), nil)
 		}
-		return m, nil
+
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -356,12 +439,38 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// ownsProject returns whether the UI owns the instances of its project, taking the project over if
+// no other process owns it, e.g. because the daemon stopped.
+func (m *home) ownsProject() bool {
+	if !m.lease.Held() {
+		if held, err := m.lease.TryAcquire(); err != nil {
+			log.WarningLog.Printf("failed to acquire the lease of the project: %v", err)
+		} else if held {
+			log.InfoLog.Printf("owning the instances of the project")
+		}
+	}
+	return m.lease.Held()
+}
+
 func (m *home) handleQuit() (tea.Model, tea.Cmd) {
-	// Save all instances for current project
-	instances := m.list.GetInstances()
-	for _, instance := range instances {
-		if err := m.projectManager.SaveInstance(instance); err != nil {
-			log.ErrorLog.Printf("Failed to save instance %s: %v", instance.Title, err)
+	// Only the owner of the project stores what it watches, like the daemon does. Everything else is
+	// stored by the operation which changed it, and instances killed or paused by another process
+	// must not be brought back with stale data.
+	if !m.lease.Held() {
+		return m, tea.Quit
+	}
+	var running []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if instance.Started() && !instance.Paused() {
+			running = append(running, instance)
+		}
+	}
+	if err := m.projectManager.SaveDiffStats(running); err != nil {
+		log.ErrorLog.Printf("failed to save diff stats: %v", err)
+	}
+	for _, instance := range running {
+		if err := m.projectManager.SaveStatus(instance); err != nil {
+			log.ErrorLog.Printf("failed to save the status of %s: %v", instance.Title, err)
 		}
 	}
 	return m, tea.Quit
@@ -412,11 +521,18 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 	}
 
 	if m.state == stateNew {
+		instance := m.newInstance
+		// Wait until the instance is created.
+		if instance.Status == session.Loading || instance.Status == session.Translating {
+			return m, nil
+		}
+
 		// Handle quit commands first. Don't handle q because the user might want to type that.
 		if msg.String() == "ctrl+c" {
 			m.state = stateDefault
 			m.promptAfterName = false
-			m.list.Kill()
+			m.list.Remove(instance)
+			m.newInstance = nil
 			return m, tea.Sequence(
 				tea.WindowSize(),
 				func() tea.Msg {
@@ -426,7 +542,6 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			)
 		}
 
-		instance := m.list.GetInstances()[m.list.NumInstances()-1]
 		switch msg.Type {
 		// Start the instance (enable previews etc) and go back to the main menu state.
 		case tea.KeyEnter:
@@ -446,57 +561,19 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 				// Set status to Translating to show spinner
 				instance.SetStatus(session.Translating)
 				// Start async translation
-				instanceIdx := m.list.NumInstances() - 1
-				return m, m.translateToEnglishCmd(instanceIdx, userInput)
+				return m, m.translateToEnglishCmd(instance, userInput)
 			} else {
 				// Pure ASCII input, both DisplayName and Title are the same
-				log.InfoLog.Printf("[PERF] Pure ASCII input '%s', starting instance directly (sync)", userInput)
+				log.InfoLog.Printf("[PERF] Pure ASCII input '%s', creating instance directly", userInput)
 				instance.DisplayName = userInput
 			}
 
-			log.InfoLog.Printf("[PERF] Starting instance '%s' synchronously", instance.Title)
-			if err := instance.Start(true); err != nil {
-				m.list.Kill()
-				m.state = stateDefault
-				return m, m.handleError(err)
-			}
-			// Save after adding new instance
-			instances := m.list.GetInstances()
-			for _, instance := range instances {
-				if err := m.projectManager.SaveInstance(instance); err != nil {
-					log.ErrorLog.Printf("Failed to save instance %s: %v", instance.Title, err)
-				}
-			}
-			// Instance added successfully, call the finalizer.
-			m.newInstanceFinalizer()
-			if m.autoYes {
-				instance.AutoYes = true
-			}
-
-			// Save after adding new instance
-			instances = m.list.GetInstances()
-			for _, instance := range instances {
-				if err := m.projectManager.SaveInstance(instance); err != nil {
-					log.ErrorLog.Printf("Failed to save instance %s: %v", instance.Title, err)
-				}
-			}
-
-			m.state = stateDefault
-			if m.promptAfterName {
-				m.state = statePrompt
-				m.menu.SetState(ui.StatePrompt)
-				// Initialize the text input overlay
-				m.textInputOverlay = overlay.NewTextInputOverlay("Enter prompt", "")
-				m.promptAfterName = false
-			} else {
-				m.menu.SetState(ui.StateDefault)
-				m.showHelpScreen(helpStart(instance), nil)
-			}
-
-			return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+			// Show the spinner while the instance is created.
+			instance.SetStatus(session.Loading)
+			return m, m.createInstanceCmd(instance)
 		case tea.KeyRunes:
-			if len(instance.Title) >= 32 {
-				return m, m.handleError(fmt.Errorf("title cannot be longer than 32 characters"))
+			if len(instance.Title) >= session.MaxTitleLength {
+				return m, m.handleError(fmt.Errorf("title cannot be longer than %d characters", session.MaxTitleLength))
 			}
 			if err := instance.SetTitle(instance.Title + string(msg.Runes)); err != nil {
 				return m, m.handleError(err)
@@ -515,7 +592,8 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 				return m, m.handleError(err)
 			}
 		case tea.KeyEsc:
-			m.list.Kill()
+			m.list.Remove(instance)
+			m.newInstance = nil
 			m.state = stateDefault
 			m.instanceChanged()
 
@@ -541,7 +619,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 				return m, nil
 			}
 			if m.textInputOverlay.IsSubmitted() {
-				if err := selected.SendPrompt(m.textInputOverlay.GetValue()); err != nil {
+				if _, err := m.controller.Send(selected, m.textInputOverlay.GetValue()); err != nil {
 					// TODO: we probably end up in a bad state here.
 					return m, m.handleError(err)
 				}
@@ -605,7 +683,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		return m.showHelpScreen(helpTypeGeneral{}, nil)
 	case keys.KeyPrompt:
 		// Check project instance limit
-		instances, err := m.projectManager.GetAllInstances()
+		instances, err := m.projectManager.GetAllInstancesData()
 		if err != nil {
 			return m, m.handleError(fmt.Errorf("failed to check instance limit: %w", err))
 		}
@@ -623,7 +701,9 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			return m, m.handleError(err)
 		}
 
-		m.newInstanceFinalizer = m.list.AddInstance(instance)
+		m.newInstance = instance
+		// The instance is only registered in the list once it is created.
+		m.list.AddInstance(instance)
 		m.list.SetSelectedInstance(m.list.NumInstances() - 1)
 		m.state = stateNew
 		m.menu.SetState(ui.StateNewInstance)
@@ -632,7 +712,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		return m, nil
 	case keys.KeyNew:
 		// Check project instance limit
-		instances, err := m.projectManager.GetAllInstances()
+		instances, err := m.projectManager.GetAllInstancesData()
 		if err != nil {
 			return m, m.handleError(fmt.Errorf("failed to check instance limit: %w", err))
 		}
@@ -650,7 +730,9 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			return m, m.handleError(err)
 		}
 
-		m.newInstanceFinalizer = m.list.AddInstance(instance)
+		m.newInstance = instance
+		// The instance is only registered in the list once it is created.
+		m.list.AddInstance(instance)
 		m.list.SetSelectedInstance(m.list.NumInstances() - 1)
 		m.state = stateNew
 		m.menu.SetState(ui.StateNewInstance)
@@ -732,8 +814,11 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 
 		// Show help screen before pausing
 		m.showHelpScreen(helpTypeInstanceCheckout{}, func() {
-			if err := selected.Pause(); err != nil {
+			paused, err := m.controller.Pause(selected)
+			if err != nil {
 				m.handleError(err)
+			} else {
+				m.replaceInstance(selected, paused)
 			}
 			m.instanceChanged()
 		})
@@ -743,9 +828,11 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		if selected == nil {
 			return m, nil
 		}
-		if err := selected.Resume(); err != nil {
+		resumed, err := m.controller.Resume(selected)
+		if err != nil {
 			return m, m.handleError(err)
 		}
+		m.replaceInstance(selected, resumed)
 		return m, tea.WindowSize()
 	case keys.KeyApply:
 		selected := m.list.GetSelectedInstance()
@@ -792,12 +879,14 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 
 			// Step 5: Perform the "checkout" operation - same as KeyCheckout
 			// This will: save changes, detach tmux, remove worktree, but keep branch
-			if err := selected.Pause(); err != nil {
+			paused, err := m.controller.Pause(selected)
+			if err != nil {
 				return fmt.Errorf("failed to pause instance during apply: %w", err)
 			}
+			m.replaceInstance(selected, paused)
 
 			// Step 6: Apply successful, now delete the instance (reusing D key logic)
-			if err := m.deleteInstance(selected); err != nil {
+			if err := m.deleteInstance(paused); err != nil {
 				return fmt.Errorf("apply succeeded but failed to delete instance: %w", err)
 			}
 
@@ -816,7 +905,18 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			return m, nil
 		}
 		selected := m.list.GetSelectedInstance()
-		if selected == nil || selected.Paused() || !selected.TmuxAlive() {
+		if selected == nil {
+			return m, nil
+		}
+		if selected.Paused() && selected.AutoPaused && m.appConfig.IdlePause.AutoResume {
+			resumed, err := m.controller.Resume(selected)
+			if err != nil {
+				return m, m.handleError(err)
+			}
+			m.replaceInstance(selected, resumed)
+			selected = resumed
+		}
+		if selected.Paused() || !selected.TmuxAlive() {
 			return m, nil
 		}
 		// Show help screen before attaching
@@ -884,17 +984,29 @@ type tickUpdateMetadataMessage struct{}
 
 type instanceChangedMsg struct{}
 
+// daemonEventMsg is sent when the daemon reports a change of an instance
+type daemonEventMsg struct {
+	event daemon.Event
+}
+
+// daemonGoneMsg is sent when the connection to the daemon is lost, e.g. because it stopped
+type daemonGoneMsg struct {
+	err error
+}
+
 // translationCompleteMsg is sent when LLM translation completes
 type translationCompleteMsg struct {
-	instanceIdx int
+	instance     *session.Instance
 	translatedID string
-	err error
+	err          error
 }
 
-// instanceStartCompleteMsg is sent when instance startup completes
+// instanceStartCompleteMsg is sent when instance startup completes. created takes the place of the
+// named instance.
 type instanceStartCompleteMsg struct {
-	instanceIdx int
-	err error
+	instance *session.Instance
+	created  *session.Instance
+	err      error
 }
 
 // tickUpdateMetadataCmd is the callback to update the metadata of the instances every 500ms. Note that we iterate
@@ -904,35 +1016,108 @@ var tickUpdateMetadataCmd = func() tea.Msg {
 	return tickUpdateMetadataMessage{}
 }
 
-// deleteInstance deletes an instance and removes it from the list
+// deleteInstance kills an instance, removes its worktree and branch and removes it from the list
 func (m *home) deleteInstance(instance *session.Instance) error {
-	// Get worktree and check if branch is checked out
-	worktree, err := instance.GetGitWorktree()
-	if err != nil {
+	// Fails if the branch is checked out.
+	if err := m.controller.Kill(instance); err != nil {
 		return err
 	}
+	m.removeInstance(instance)
+	return nil
+}
 
-	checkedOut, err := worktree.IsBranchCheckedOut()
-	if err != nil {
-		return err
+// replaceInstance puts the new copy of an instance, e.g. the daemon returned after pausing it, in
+// the place of the old one.
+func (m *home) replaceInstance(old, instance *session.Instance) {
+	if old == instance {
+		return
 	}
+	// The session of the old copy is gone or owned by the new one.
+	if err := old.ReleasePTY(); err != nil {
+		log.WarningLog.Printf("failed to release the PTY of %s: %v", old.Title, err)
+	}
+	m.list.Replace(old, instance)
+}
 
-	if checkedOut {
-		displayName := instance.DisplayName
-		if displayName == "" {
-			displayName = instance.Title
+// removeInstance removes a killed instance from the list.
+func (m *home) removeInstance(instance *session.Instance) {
+	if err := instance.ReleasePTY(); err != nil {
+		log.WarningLog.Printf("failed to release the PTY of %s: %v", instance.Title, err)
+	}
+	m.list.Remove(instance)
+}
+
+// nextEventCmd waits for the next change the daemon reports.
+func (m *home) nextEventCmd() tea.Cmd {
+	events := m.events
+	return func() tea.Msg {
+		event, err := events.NextEvent()
+		if err != nil {
+			return daemonGoneMsg{err: err}
 		}
-		return fmt.Errorf("instance %s is currently checked out", displayName)
+		return daemonEventMsg{event: event}
 	}
+}
 
-	// Delete from storage first
-	if err := m.projectManager.DeleteInstance(instance.Title); err != nil {
-		return err
+// handleDaemonEvent applies a change of an instance the daemon reported to the list.
+func (m *home) handleDaemonEvent(event daemon.Event) {
+	if event.Type == daemon.EventResync {
+		m.resync(event.Instances)
+		return
+	}
+	data := event.Instance
+	if data.ProjectID != m.projectManager.GetProjectID() {
+		return
+	}
+	var existing *session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if instance.Title == data.Title {
+			existing = instance
+			break
+		}
 	}
 
-	// Then kill the instance
-	m.list.Kill()
-	return nil
+	switch event.Type {
+	case daemon.EventRemoved:
+		if existing != nil && existing.Started() {
+			m.removeInstance(existing)
+		}
+	case daemon.EventStatus:
+		switch {
+		case existing == nil:
+			// Created by another frontend, e.g. `cs new` or a schedule.
+			m.list.AddInstance(session.FromInstanceDataDetached(data))()
+		case !existing.Started():
+			// The instance being named. It is replaced once it is created.
+		case existing.Paused() != (data.Status == session.Paused):
+			// Pausing and resuming replace the worktree and the session, so take the daemon's copy.
+			m.replaceInstance(existing, session.FromInstanceDataDetached(data))
+		default:
+			existing.ApplyStatus(data)
+		}
+	}
+}
+
+// resync brings the list in line with all instances the daemon reported after the UI fell behind.
+func (m *home) resync(instances []session.InstanceData) {
+	reported := make(map[string]bool, len(instances))
+	for _, data := range instances {
+		if data.ProjectID != m.projectManager.GetProjectID() {
+			continue
+		}
+		reported[data.Title] = true
+		m.handleDaemonEvent(daemon.Event{Type: daemon.EventStatus, Instance: data})
+	}
+	var removed []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		// Instances being named are not stored yet.
+		if instance.Started() && !reported[instance.Title] {
+			removed = append(removed, instance)
+		}
+	}
+	for _, instance := range removed {
+		m.removeInstance(instance)
+	}
 }
 
 // handleError handles all errors which get bubbled up to the app. sets the error message. We return a callback tea.Cmd that returns a hideErrMsg message
@@ -951,7 +1136,7 @@ func (m *home) handleError(err error) tea.Cmd {
 }
 
 // translateToEnglishCmd creates a tea.Cmd that asynchronously translates a name to English
-func (m *home) translateToEnglishCmd(instanceIdx int, chineseName string) tea.Cmd {
+func (m *home) translateToEnglishCmd(instance *session.Instance, chineseName string) tea.Cmd {
 	return func() tea.Msg {
 		log.InfoLog.Printf("[PERF] Starting LLM translation for '%s'", chineseName)
 		start := time.Now()
@@ -967,36 +1152,36 @@ func (m *home) translateToEnglishCmd(instanceIdx int, chineseName string) tea.Cm
 			translatedID = fmt.Sprintf("session-%d", time.Now().Unix())
 		}
 		return translationCompleteMsg{
-			instanceIdx: instanceIdx,
+			instance:     instance,
 			translatedID: translatedID,
-			err: err,
+			err:          err,
 		}
 	}
 }
 
-// startInstanceCmd creates a tea.Cmd that asynchronously starts an instance
-func (m *home) startInstanceCmd(instanceIdx int, firstTimeSetup bool) tea.Cmd {
+// createInstanceCmd creates a tea.Cmd that asynchronously creates and starts the named instance,
+// through the daemon if it runs
+func (m *home) createInstanceCmd(instance *session.Instance) tea.Cmd {
+	opts := session.InstanceOptions{
+		Title:       instance.Title,
+		DisplayName: instance.DisplayName,
+		Path:        ".",
+		Program:     m.program,
+		AutoYes:     m.autoYes,
+	}
 	return func() tea.Msg {
-		instances := m.list.GetInstances()
-		if instanceIdx < 0 || instanceIdx >= len(instances) {
-			return instanceStartCompleteMsg{
-				instanceIdx: instanceIdx,
-				err: fmt.Errorf("invalid instance index: %d", instanceIdx),
-			}
-		}
-
-		instance := instances[instanceIdx]
-		log.InfoLog.Printf("[PERF] Starting instance.Start() for '%s' (firstTimeSetup: %v)", instance.Title, firstTimeSetup)
+		log.InfoLog.Printf("[PERF] Creating instance '%s'", opts.Title)
 		start := time.Now()
 
-		err := instance.Start(firstTimeSetup)
+		created, err := m.controller.Create(opts)
 
 		elapsed := time.Since(start)
-		log.InfoLog.Printf("[PERF] instance.Start() completed in %v (error: %v)", elapsed, err)
+		log.InfoLog.Printf("[PERF] Creating instance completed in %v (error: %v)", elapsed, err)
 
 		return instanceStartCompleteMsg{
-			instanceIdx: instanceIdx,
-			err: err,
+			instance: instance,
+			created:  created,
+			err:      err,
 		}
 	}
 }
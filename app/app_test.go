@@ -2,6 +2,7 @@ package app
 
 import (
 	"claude-squad/config"
+	"claude-squad/daemon"
 	"claude-squad/log"
 	"claude-squad/session"
 	"claude-squad/ui"
@@ -464,3 +465,73 @@ func TestConfirmationModalVisualAppearance(t *testing.T) {
 	// Test that the danger indicator is preserved
 	assert.Contains(t, rendered, "[!")
 }
+
+// TestHandleDaemonEvent tests that the list follows the changes the daemon reports
+func TestHandleDaemonEvent(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	repo := t.TempDir()
+	h := &home{
+		ctx:            context.Background(),
+		state:          stateDefault,
+		appConfig:      config.DefaultConfig(),
+		list:           ui.NewList(&spinner, false),
+		menu:           ui.NewMenu(),
+		projectManager: session.NewProjectInstanceManager("project", repo, t.TempDir()),
+	}
+	data := func(title string, status session.Status) session.InstanceData {
+		return session.InstanceData{
+			Title:     title,
+			Path:      repo,
+			Program:   "claude",
+			Status:    status,
+			ProjectID: "project",
+			Worktree:  session.GitWorktreeData{RepoPath: repo, BranchName: "test/" + title},
+		}
+	}
+
+	// Instances created by another frontend are added, those of other projects are ignored.
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventStatus, Instance: data("foo", session.Running)})
+	other := data("bar", session.Running)
+	other.ProjectID = "other"
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventStatus, Instance: other})
+	require.Equal(t, 1, h.list.NumInstances())
+	foo := h.list.GetInstances()[0]
+	assert.Equal(t, "foo", foo.Title)
+	assert.True(t, foo.Started())
+
+	// Status changes are applied to the instance.
+	ready := data("foo", session.Ready)
+	ready.NeedsApproval = true
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventStatus, Instance: ready})
+	assert.Same(t, foo, h.list.GetInstances()[0])
+	assert.Equal(t, session.Ready, foo.Status)
+	assert.True(t, foo.NeedsApproval)
+
+	// A paused instance is replaced by the daemon's copy.
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventStatus, Instance: data("foo", session.Paused)})
+	require.Equal(t, 1, h.list.NumInstances())
+	assert.NotSame(t, foo, h.list.GetInstances()[0])
+	assert.True(t, h.list.GetInstances()[0].Paused())
+
+	// The instance being named is left alone until it is created.
+	named, err := session.NewInstance(session.InstanceOptions{Title: "new", Path: repo, Program: "claude"})
+	require.NoError(t, err)
+	h.list.AddInstance(named)
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventStatus, Instance: data("new", session.Running)})
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventRemoved, Instance: data("new", session.Running)})
+	require.Equal(t, 2, h.list.NumInstances())
+	assert.Same(t, named, h.list.GetInstances()[1])
+
+	// Killed instances are removed.
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventRemoved, Instance: data("foo", session.Paused)})
+	require.Equal(t, 1, h.list.NumInstances())
+	assert.Same(t, named, h.list.GetInstances()[0])
+
+	// A resync adds the reported instances and removes the others, except the one being named.
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventResync, Instances: []session.InstanceData{data("foo", session.Running), other}})
+	require.Equal(t, 2, h.list.NumInstances())
+	assert.Equal(t, "foo", h.list.GetInstances()[1].Title)
+	h.handleDaemonEvent(daemon.Event{Type: daemon.EventResync})
+	require.Equal(t, 1, h.list.NumInstances())
+	assert.Same(t, named, h.list.GetInstances()[0])
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	attachResumeFlag bool
+
+	attachCmd = &cobra.Command{
+		Use:   "attach <title>",
+		Short: "Attach to an instance directly, without the UI. Detach with ctrl-q",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return fmt.Errorf("attach requires an interactive terminal")
+			}
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			instance, err := projectManager.GetInstanceDetached(args[0])
+			if err != nil {
+				return err
+			}
+
+			if instance.Paused() {
+				autoResume := instance.AutoPaused && config.LoadConfig().IdlePause.AutoResume
+				if !attachResumeFlag && !autoResume {
+					return fmt.Errorf("instance '%s' is paused, use --resume to resume it first", instance.Title)
+				}
+				if err := instance.Resume(); err != nil {
+					return fmt.Errorf("failed to resume instance: %w", err)
+				}
+				if err := projectManager.UpdateInstance(instance); err != nil {
+					return err
+				}
+			}
+			if !instance.TmuxAlive() {
+				return fmt.Errorf("tmux session of instance '%s' is not running", instance.Title)
+			}
+
+			oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+			if err != nil {
+				return fmt.Errorf("failed to put terminal into raw mode: %w", err)
+			}
+			defer func() {
+				_ = term.Restore(int(os.Stdin.Fd()), oldState)
+				// The tmux client is killed on detach and doesn't get to leave the alternate screen or
+				// show the cursor again, so do it for it.
+				fmt.Print("\033[?1049l\033[?25h")
+			}()
+
+			ch, err := instance.Attach()
+			if err != nil {
+				return err
+			}
+			<-ch
+			return nil
+		},
+	}
+)
+
+func init() {
+	attachCmd.Flags().BoolVar(&attachResumeFlag, "resume", false, "Resume the instance first if it is paused")
+
+	rootCmd.AddCommand(attachCmd)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// auditPromptWidth is how much of a prompt the table shows. --json prints prompts in full.
+const auditPromptWidth = 80
+
+var (
+	auditInstanceFlag string
+	auditProjectFlag  string
+	auditSinceFlag    string
+	auditJSONFlag     bool
+
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Show the prompts which were accepted automatically",
+		Long: "Show the prompts which the daemon or the UI accepted for instances in auto-yes mode, " +
+			"oldest first. They are recorded in audit.jsonl in the config directory.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			filter := session.AuditFilter{Instance: auditInstanceFlag}
+			if auditSinceFlag != "" {
+				since, err := parseSince(auditSinceFlag, time.Now())
+				if err != nil {
+					return err
+				}
+				filter.Since = since
+			}
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			if auditProjectFlag != "" {
+				project, err := resolveProject(instanceManager, auditProjectFlag)
+				if err != nil {
+					return err
+				}
+				filter.ProjectID = project.ID
+			}
+
+			records, err := session.ReadAuditRecords(filter)
+			if err != nil {
+				return err
+			}
+
+			if auditJSONFlag {
+				// One record per line, like the audit log itself.
+				encoder := json.NewEncoder(os.Stdout)
+				for _, record := range records {
+					if err := encoder.Encode(record); err != nil {
+						return fmt.Errorf("failed to marshal audit record: %w", err)
+					}
+				}
+				return nil
+			}
+
+			projectNames := make(map[string]string)
+			if projects, err := instanceManager.GetAllProjects(); err == nil {
+				for _, project := range projects {
+					projectNames[project.ID] = project.Name
+				}
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tPROJECT\tINSTANCE\tSOURCE\tPROMPT")
+			for _, record := range records {
+				project := projectNames[record.ProjectID]
+				if project == "" {
+					project = record.ProjectID
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", record.Time.Local().Format("2006-01-02 15:04:05"),
+					orDash(project), record.Instance, orDash(record.Source), summarizePrompt(record.Prompt))
+			}
+			return w.Flush()
+		},
+	}
+)
+
+// parseSince parses a duration like "1h" before now, or a date or time like "2024-05-01" or
+// "2024-05-01T15:04:05Z".
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("--since cannot be negative")
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q, must be a duration like 1h or a date like 2024-05-01", value)
+}
+
+// summarizePrompt joins the lines of a prompt and cuts it to auditPromptWidth characters.
+func summarizePrompt(prompt string) string {
+	fields := strings.FieldsFunc(prompt, func(r rune) bool {
+		// Box borders carry no information.
+		return r == ' ' || r == '\t' || r == '\n' || r == '│' || r == '╭' || r == '╰' || r == '─'
+	})
+	summary := []rune(strings.Join(fields, " "))
+	if len(summary) > auditPromptWidth {
+		return string(summary[:auditPromptWidth-3]) + "..."
+	}
+	return string(summary)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditInstanceFlag, "instance", "", "Only show prompts of instances with this title")
+	auditCmd.Flags().StringVar(&auditProjectFlag, "project", "",
+		"Only show prompts of the given project (ID, ID prefix, name or repository path)")
+	auditCmd.Flags().StringVar(&auditSinceFlag, "since", "",
+		"Only show prompts accepted in the given duration before now (e.g. 1h) or since a date (e.g. 2024-05-01)")
+	auditCmd.Flags().BoolVar(&auditJSONFlag, "json", false, "Print the records as JSON, one per line")
+
+	rootCmd.AddCommand(auditCmd)
+}
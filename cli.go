@@ -0,0 +1,81 @@
+package main
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"fmt"
+	"time"
+)
+
+// newInstanceManager returns an instance manager rooted at the application's config directory.
+func newInstanceManager() (*session.InstanceManager, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return session.NewInstanceManager(configDir), nil
+}
+
+// currentProjectManager returns the project manager for the git repository containing the
+// current working directory.
+func currentProjectManager() (*session.ProjectInstanceManager, error) {
+	instanceManager, err := newInstanceManager()
+	if err != nil {
+		return nil, err
+	}
+	projectManager, err := instanceManager.GetCurrentProjectManager()
+	if err != nil {
+		return nil, fmt.Errorf("claude-squad must be run from within a git repository: %w", err)
+	}
+	return projectManager, nil
+}
+
+// acquireProjectLease takes the lease of a project for a command deleting its instances, so that no
+// daemon or UI owning the project restarts or stores them meanwhile. It fails if another process owns
+// the project.
+func acquireProjectLease(projectManager *session.ProjectInstanceManager) (*session.Lease, error) {
+	lease := projectManager.NewLease("cli")
+	held, err := lease.TryAcquire()
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return lease, nil
+	}
+	holder, err := projectManager.LeaseHolder()
+	if err == nil && holder != nil && holder.Owner == "daemon" {
+		return nil, fmt.Errorf("the daemon (PID %d) owns the project, stop it with `cs daemon stop` first", holder.PID)
+	}
+	return nil, fmt.Errorf("another process owns the project, quit the claude-squad UI running in it first")
+}
+
+// formatAge formats the time elapsed since t in a compact form like "5m" or "3d".
+func formatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case t.IsZero():
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// formatSize formats a number of bytes in a human readable form like "512B" or "1.5GB".
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
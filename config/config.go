@@ -1,8 +1,10 @@
 package config
 
 import (
+	"bytes"
 	"claude-squad/log"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const (
@@ -43,6 +46,22 @@ type LLMConfig struct {
 	EnableThinking bool `json:"enable_thinking,omitempty"`
 }
 
+// IdlePauseConfig controls the pausing of instances nobody uses, so that their worktrees don't take up
+// disk space.
+type IdlePauseConfig struct {
+	// After is the number of minutes an instance has to be Ready without uncommitted changes before
+	// it is paused. Zero disables pausing idle instances.
+	After int `json:"after"`
+	// AutoResume resumes instances which were paused for being idle when a prompt is sent to them or
+	// they are attached to.
+	AutoResume bool `json:"auto_resume"`
+}
+
+// Duration returns how long an instance has to be idle before it is paused, zero if never.
+func (c IdlePauseConfig) Duration() time.Duration {
+	return time.Duration(c.After) * time.Minute
+}
+
 // Config represents the application configuration
 type Config struct {
 	// DefaultProgram is the default program to run in new instances
@@ -55,6 +74,12 @@ type Config struct {
 	BranchPrefix string `json:"branch_prefix"`
 	// LLM is the configuration for LLM translation service
 	LLM LLMConfig `json:"llm"`
+	// ApprovalPolicy decides which prompts AutoYes mode accepts. Denied prompts are left for a human.
+	ApprovalPolicy ApprovalPolicy `json:"approval_policy"`
+	// IdlePause pauses instances which have been idle for a while.
+	IdlePause IdlePauseConfig `json:"idle_pause"`
+	// Schedules are actions the daemon runs periodically.
+	Schedules []Schedule `json:"schedules,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -86,6 +111,11 @@ func DefaultConfig() *Config {
 			Stream:  false, // Disable streaming by default
 			EnableThinking: false,
 		},
+		ApprovalPolicy: DefaultApprovalPolicy(),
+		IdlePause: IdlePauseConfig{
+			After:      0, // Disabled by default
+			AutoResume: false,
+		},
 	}
 }
 
@@ -137,17 +167,104 @@ func GetClaudeCommand() (string, error) {
 	return "", fmt.Errorf("claude command not found in aliases or PATH")
 }
 
-func LoadConfig() *Config {
+// GetConfigPath returns the path to the config file
+func GetConfigPath() (string, error) {
 	configDir, err := GetConfigDir()
 	if err != nil {
-		log.ErrorLog.Printf("failed to get config directory: %v", err)
-		return DefaultConfig()
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, ConfigFileName), nil
+}
+
+// Validate checks the config for values the application cannot work with
+func (c *Config) Validate() error {
+	var errs []error
+	if strings.TrimSpace(c.DefaultProgram) == "" {
+		errs = append(errs, fmt.Errorf("default_program cannot be empty"))
+	}
+	if c.DaemonPollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("daemon_poll_interval must be a positive number of milliseconds, got %d", c.DaemonPollInterval))
+	}
+	if c.LLM.Enabled {
+		if c.LLM.Model == "" {
+			errs = append(errs, fmt.Errorf("llm.model is required when llm.enabled is true"))
+		}
+		if c.LLM.BaseURL == "" {
+			errs = append(errs, fmt.Errorf("llm.base_url is required when llm.enabled is true"))
+		}
+	}
+	if c.LLM.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("llm.timeout cannot be negative, got %d", c.LLM.Timeout))
+	}
+	if c.IdlePause.After < 0 {
+		errs = append(errs, fmt.Errorf("idle_pause.after cannot be negative, got %d", c.IdlePause.After))
+	}
+	if err := c.ApprovalPolicy.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateSchedules(c.Schedules); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ParseConfig parses the content of a config file strictly, rejecting unknown keys, and validates it
+func ParseConfig(data []byte) (*Config, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("failed to parse config: unexpected data after the config object")
+	}
+	applyMissingDefaults(&config, data)
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// ReadConfig reads and parses the config file without falling back to the default config. The
+// returned error wraps os.ErrNotExist if there is no config file.
+func ReadConfig() (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
 	}
 
-	configPath := filepath.Join(configDir, ConfigFileName)
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to get config file: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	applyMissingDefaults(&config, data)
+	return &config, nil
+}
+
+// applyMissingDefaults sets the defaults of settings which a config file written before they
+// existed lacks, where their zero value would be less safe than the default.
+func applyMissingDefaults(config *Config, data []byte) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return
+	}
+	// Without a policy, AutoYes mode would accept every prompt, including `rm -rf`.
+	if _, ok := keys["approval_policy"]; !ok {
+		config.ApprovalPolicy = DefaultApprovalPolicy()
+	}
+}
+
+func LoadConfig() *Config {
+	config, err := ReadConfig()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
 			// Create and save default config if file doesn't exist
 			defaultCfg := DefaultConfig()
 			if saveErr := saveConfig(defaultCfg); saveErr != nil {
@@ -156,14 +273,12 @@ func LoadConfig() *Config {
 			return defaultCfg
 		}
 
-		log.WarningLog.Printf("failed to get config file: %v", err)
+		log.ErrorLog.Printf("%v", err)
 		return DefaultConfig()
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		log.ErrorLog.Printf("failed to parse config file: %v", err)
-		return DefaultConfig()
+	if err := config.Validate(); err != nil {
+		log.WarningLog.Printf("invalid config, run 'claude-squad config validate' for details: %v", err)
 	}
 
 	// Ensure LLM config has default values if not set
@@ -171,7 +286,7 @@ func LoadConfig() *Config {
 		config.LLM.Enabled = false
 	}
 
-	return &config
+	return config
 }
 
 // saveConfig saves the configuration to disk
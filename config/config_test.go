@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -202,6 +203,129 @@ func TestLoadConfig(t *testing.T) {
 	})
 }
 
+func TestReadConfig(t *testing.T) {
+	t.Run("returns not exist error when file doesn't exist", func(t *testing.T) {
+		originalHome := os.Getenv("HOME")
+		os.Setenv("HOME", t.TempDir())
+		defer os.Setenv("HOME", originalHome)
+
+		config, err := ReadConfig()
+
+		assert.Nil(t, config)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("returns parse error on invalid JSON", func(t *testing.T) {
+		tempHome := t.TempDir()
+		configDir := filepath.Join(tempHome, ".claude-squad")
+		require.NoError(t, os.MkdirAll(configDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, ConfigFileName), []byte(`{"auto_yes": tru}`), 0644))
+
+		originalHome := os.Getenv("HOME")
+		os.Setenv("HOME", tempHome)
+		defer os.Setenv("HOME", originalHome)
+
+		config, err := ReadConfig()
+
+		assert.Nil(t, config)
+		assert.ErrorContains(t, err, "failed to parse config file")
+	})
+
+	t.Run("denies destructive prompts with a config written before approval policies", func(t *testing.T) {
+		tempHome := t.TempDir()
+		configDir := filepath.Join(tempHome, ".claude-squad")
+		require.NoError(t, os.MkdirAll(configDir, 0755))
+		configContent := `{"default_program": "claude", "auto_yes": true, "daemon_poll_interval": 1000, "branch_prefix": "test/"}`
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, ConfigFileName), []byte(configContent), 0644))
+
+		originalHome := os.Getenv("HOME")
+		os.Setenv("HOME", tempHome)
+		defer os.Setenv("HOME", originalHome)
+
+		config, err := ReadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultApprovalPolicy(), config.ApprovalPolicy)
+		allowed, reason := config.ApprovalMatcher().Allows("claude", "Do you want to run rm -rf /?")
+		assert.False(t, allowed)
+		assert.NotEmpty(t, reason)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{DefaultProgram: "claude", DaemonPollInterval: 1000, BranchPrefix: "test/"}
+	}
+
+	t.Run("accepts valid config", func(t *testing.T) {
+		assert.NoError(t, valid().Validate())
+	})
+
+	t.Run("rejects bad daemon poll interval", func(t *testing.T) {
+		config := valid()
+		config.DaemonPollInterval = 0
+		assert.ErrorContains(t, config.Validate(), "daemon_poll_interval")
+	})
+
+	t.Run("rejects enabled LLM without model and base URL", func(t *testing.T) {
+		config := valid()
+		config.LLM.Enabled = true
+		err := config.Validate()
+		assert.ErrorContains(t, err, "llm.model is required")
+		assert.ErrorContains(t, err, "llm.base_url is required")
+
+		config.LLM.Model = "model"
+		config.LLM.BaseURL = "http://localhost:11434"
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("rejects negative idle pause", func(t *testing.T) {
+		config := valid()
+		config.IdlePause.After = -1
+		assert.ErrorContains(t, config.Validate(), "idle_pause.after")
+
+		config.IdlePause.After = 30
+		assert.NoError(t, config.Validate())
+		assert.Equal(t, 30*time.Minute, config.IdlePause.Duration())
+	})
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Run("parses valid config", func(t *testing.T) {
+		config, err := ParseConfig([]byte(`{"default_program": "claude", "daemon_poll_interval": 500}`))
+		require.NoError(t, err)
+		assert.Equal(t, "claude", config.DefaultProgram)
+		assert.Equal(t, 500, config.DaemonPollInterval)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		_, err := ParseConfig([]byte(`{"default_program": "claude", "daemon_poll_interval": 500, "autoyes": true}`))
+		assert.ErrorContains(t, err, `unknown field "autoyes"`)
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		_, err := ParseConfig([]byte(`{"default_program": "claude", "daemon_poll_interval": -1}`))
+		assert.ErrorContains(t, err, "daemon_poll_interval")
+	})
+
+	t.Run("rejects trailing data", func(t *testing.T) {
+		_, err := ParseConfig([]byte(`{"default_program": "claude", "daemon_poll_interval": 500} {}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("applies the default approval policy to configs without one", func(t *testing.T) {
+		config, err := ParseConfig([]byte(`{"default_program": "claude", "daemon_poll_interval": 500}`))
+		require.NoError(t, err)
+		allowed, _ := config.ApprovalMatcher().Allows("claude", "Run rm -rf /?")
+		assert.False(t, allowed)
+
+		config, err = ParseConfig([]byte(`{"default_program": "claude", "daemon_poll_interval": 500,
+			"approval_policy": {"default": {"allow": [], "deny": []}}}`))
+		require.NoError(t, err)
+		allowed, _ = config.ApprovalMatcher().Allows("claude", "Run rm -rf /?")
+		assert.True(t, allowed, "an explicit policy is kept")
+	})
+}
+
 func TestSaveConfig(t *testing.T) {
 	t.Run("saves config to file", func(t *testing.T) {
 		// Create a temporary config directory
@@ -24,6 +24,8 @@ type GlobalProjectData struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	InstanceCount int     `json:"instance_count"`
+	// RestartPolicy controls whether dead sessions of the project's instances are restarted.
+	RestartPolicy *RestartPolicy `json:"restart_policy,omitempty"`
 }
 
 // GlobalState represents the global application state
@@ -202,6 +204,29 @@ func (gsm *GlobalStateManager) UpdateProjectInstanceCount(projectID string, coun
 	return fmt.Errorf("project not found: %s", projectID)
 }
 
+// SetProjectRestartPolicy sets the restart policy of a project. A nil policy disables restarts.
+func (gsm *GlobalStateManager) SetProjectRestartPolicy(projectID string, policy *RestartPolicy) error {
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("invalid restart policy: %w", err)
+		}
+	}
+	state, err := gsm.GetOrCreateGlobalState()
+	if err != nil {
+		return err
+	}
+
+	for i := range state.Projects {
+		if state.Projects[i].ID == projectID {
+			state.Projects[i].RestartPolicy = policy
+			state.Projects[i].UpdatedAt = time.Now()
+			return gsm.SaveGlobalState()
+		}
+	}
+
+	return fmt.Errorf("project not found: %s", projectID)
+}
+
 // GetAllProjects returns all projects in global state
 func (gsm *GlobalStateManager) GetAllProjects() ([]GlobalProjectData, error) {
 	state, err := gsm.GetOrCreateGlobalState()
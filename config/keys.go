@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lookupKey returns the field of the struct v addressed by a dotted JSON key like "llm.model".
+func lookupKey(v reflect.Value, key string) (reflect.Value, error) {
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown config key %q", key)
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if jsonName(v.Type().Field(i)) == part {
+				v = v.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown config key %q, valid keys are: %s", key, strings.Join(Keys(), ", "))
+		}
+	}
+	return v, nil
+}
+
+// jsonName returns the JSON key of a struct field.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// Keys returns all dotted config keys, e.g. "default_program" and "llm.model".
+func Keys() []string {
+	var keys []string
+	var collect func(t reflect.Type, prefix string)
+	collect = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := prefix + jsonName(field)
+			if field.Type.Kind() == reflect.Struct {
+				collect(field.Type, key+".")
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	collect(reflect.TypeOf(Config{}), "")
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value of a dotted config key. Nested objects are returned as JSON.
+func (c *Config) Get(key string) (string, error) {
+	v, err := lookupKey(reflect.ValueOf(c).Elem(), key)
+	if err != nil {
+		return "", err
+	}
+	if v.Kind() == reflect.Struct {
+		data, err := json.MarshalIndent(v.Interface(), "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// Set parses value according to the type of the dotted config key and sets it. Nested objects
+// have to be given as JSON. The config is not validated.
+func (c *Config) Set(key string, value string) error {
+	v, err := lookupKey(reflect.ValueOf(c).Elem(), key)
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", key, value)
+		}
+		v.SetInt(int64(n))
+	default:
+		target := reflect.New(v.Type())
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(target.Interface()); err != nil {
+			return fmt.Errorf("%s must be a JSON value: %w", key, err)
+		}
+		v.Set(target.Elem())
+	}
+	return nil
+}
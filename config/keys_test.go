@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+
+	assert.Contains(t, keys, "default_program")
+	assert.Contains(t, keys, "daemon_poll_interval")
+	assert.Contains(t, keys, "llm.model")
+	assert.NotContains(t, keys, "llm")
+}
+
+func TestConfigGetSet(t *testing.T) {
+	config := &Config{DefaultProgram: "claude", DaemonPollInterval: 1000}
+
+	t.Run("sets and gets typed values", func(t *testing.T) {
+		require.NoError(t, config.Set("auto_yes", "true"))
+		require.NoError(t, config.Set("daemon_poll_interval", "250"))
+		require.NoError(t, config.Set("llm.model", "gpt-4o-mini"))
+
+		assert.True(t, config.AutoYes)
+		assert.Equal(t, 250, config.DaemonPollInterval)
+		assert.Equal(t, "gpt-4o-mini", config.LLM.Model)
+
+		value, err := config.Get("daemon_poll_interval")
+		require.NoError(t, err)
+		assert.Equal(t, "250", value)
+
+		value, err = config.Get("llm.model")
+		require.NoError(t, err)
+		assert.Equal(t, "gpt-4o-mini", value)
+	})
+
+	t.Run("gets and sets nested objects as JSON", func(t *testing.T) {
+		require.NoError(t, config.Set("llm", `{"enabled": true, "model": "m", "base_url": "http://localhost"}`))
+		assert.Equal(t, LLMConfig{Enabled: true, Model: "m", BaseURL: "http://localhost"}, config.LLM)
+
+		value, err := config.Get("llm")
+		require.NoError(t, err)
+		assert.Contains(t, value, `"base_url": "http://localhost"`)
+
+		assert.Error(t, config.Set("llm", `{"unknown": 1}`))
+	})
+
+	t.Run("rejects unknown keys and bad values", func(t *testing.T) {
+		_, err := config.Get("nope")
+		assert.ErrorContains(t, err, `unknown config key "nope"`)
+		assert.ErrorContains(t, config.Set("llm.nope", "x"), `unknown config key "llm.nope"`)
+		assert.ErrorContains(t, config.Set("default_program.x", "x"), "unknown config key")
+		assert.ErrorContains(t, config.Set("auto_yes", "maybe"), "must be true or false")
+		assert.ErrorContains(t, config.Set("daemon_poll_interval", "fast"), "must be an integer")
+	})
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"claude-squad/log"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ApprovalRules are regular expressions matched against the approval prompt an instance shows.
+type ApprovalRules struct {
+	// Allow lists prompts which may be accepted. If no allow rule applies to a program, every prompt
+	// which is not denied is accepted.
+	Allow []string `json:"allow"`
+	// Deny lists prompts which are left for a human. Deny rules win over allow rules.
+	Deny []string `json:"deny"`
+}
+
+// ApprovalPolicy decides which prompts AutoYes mode accepts.
+type ApprovalPolicy struct {
+	// Default holds the rules for all programs.
+	Default ApprovalRules `json:"default"`
+	// Programs holds additional rules per program, keyed by the name of the program's executable,
+	// e.g. "claude" or "aider".
+	Programs map[string]ApprovalRules `json:"programs,omitempty"`
+}
+
+// DefaultApprovalPolicy returns a policy which accepts edits and most commands, but leaves
+// destructive and network commands for a human.
+func DefaultApprovalPolicy() ApprovalPolicy {
+	return ApprovalPolicy{
+		Default: ApprovalRules{
+			Allow: []string{},
+			Deny: []string{
+				`\brm\s+(-\w+\s+)*-\w*[rRf]`,
+				`\bgit\s+push\b.*(\s-f\b|--force)`,
+				`\bgit\s+(reset\s+--hard|clean\s+-\w*f)`,
+				`\b(curl|wget|ssh|scp|rsync|nc)\s`,
+			},
+		},
+	}
+}
+
+// Validate checks that all rules are valid regular expressions.
+func (p ApprovalPolicy) Validate() error {
+	_, err := compileApprovalPolicy(p)
+	return err
+}
+
+// ApprovalMatcher is a compiled ApprovalPolicy. A nil ApprovalMatcher accepts every prompt.
+type ApprovalMatcher struct {
+	defaults compiledRules
+	programs map[string]compiledRules
+	// denyAll is set if the policy is invalid, so that nothing is accepted by accident.
+	denyAll bool
+}
+
+type compiledRules struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// ApprovalMatcher compiles the approval policy. An invalid policy is logged and results in a
+// matcher which accepts nothing.
+func (c *Config) ApprovalMatcher() *ApprovalMatcher {
+	matcher, err := compileApprovalPolicy(c.ApprovalPolicy)
+	if err != nil {
+		log.ErrorLog.Printf("invalid approval policy, no prompts will be accepted automatically: %v", err)
+		return &ApprovalMatcher{denyAll: true}
+	}
+	return matcher
+}
+
+func compileApprovalPolicy(p ApprovalPolicy) (*ApprovalMatcher, error) {
+	var errs []error
+	compile := func(key string, rules ApprovalRules) compiledRules {
+		var compiled compiledRules
+		for i, pattern := range rules.Allow {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s.allow[%d] is not a valid regular expression: %w", key, i, err))
+				continue
+			}
+			compiled.allow = append(compiled.allow, re)
+		}
+		for i, pattern := range rules.Deny {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s.deny[%d] is not a valid regular expression: %w", key, i, err))
+				continue
+			}
+			compiled.deny = append(compiled.deny, re)
+		}
+		return compiled
+	}
+
+	matcher := &ApprovalMatcher{
+		defaults: compile("approval_policy.default", p.Default),
+		programs: make(map[string]compiledRules),
+	}
+	// Sorted so that errors are reported in a stable order.
+	names := make([]string, 0, len(p.Programs))
+	for name := range p.Programs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		matcher.programs[name] = compile("approval_policy.programs."+name, p.Programs[name])
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return matcher, nil
+}
+
+// programName returns the name of the executable of a program command like "aider --model x".
+func programName(program string) string {
+	fields := strings.Fields(program)
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
+
+// Allows reports whether the prompt shown by an instance running program may be accepted
+// automatically. If not, the reason names the rule which prevents it.
+func (m *ApprovalMatcher) Allows(program, prompt string) (allowed bool, reason string) {
+	if m == nil {
+		return true, ""
+	}
+	if m.denyAll {
+		return false, "the approval policy is invalid"
+	}
+
+	rules := []compiledRules{m.defaults}
+	if programRules, ok := m.programs[programName(program)]; ok {
+		rules = append(rules, programRules)
+	}
+	hasAllowRules := false
+	allowed = false
+	for _, r := range rules {
+		for _, re := range r.deny {
+			if re.MatchString(prompt) {
+				return false, fmt.Sprintf("denied by %q", re.String())
+			}
+		}
+		hasAllowRules = hasAllowRules || len(r.allow) > 0
+		for _, re := range r.allow {
+			allowed = allowed || re.MatchString(prompt)
+		}
+	}
+	if hasAllowRules && !allowed {
+		return false, "not matched by any allow rule"
+	}
+	return true, ""
+}
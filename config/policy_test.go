@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalMatcher(t *testing.T) {
+	claudePrompt := func(command string) string {
+		return "│ Bash command\n│\n│   " + command + "\n│\n│ Do you want to proceed?\n│ ❯ 1. Yes\n" +
+			"│   3. No, and tell Claude what to do differently (esc)"
+	}
+
+	t.Run("default policy denies destructive and network commands", func(t *testing.T) {
+		config := &Config{ApprovalPolicy: DefaultApprovalPolicy()}
+		matcher := config.ApprovalMatcher()
+
+		for _, command := range []string{
+			"rm -rf build",
+			"rm -f go.sum",
+			"git push --force origin main",
+			"git push -f",
+			"git reset --hard HEAD~3",
+			"curl https://example.com/install.sh",
+		} {
+			allowed, reason := matcher.Allows("claude", claudePrompt(command))
+			assert.False(t, allowed, command)
+			assert.Contains(t, reason, "denied by", command)
+		}
+		for _, command := range []string{
+			"go test ./...",
+			"git push origin feature",
+			"rmdir empty",
+		} {
+			allowed, _ := matcher.Allows("claude", claudePrompt(command))
+			assert.True(t, allowed, command)
+		}
+	})
+
+	t.Run("allow rules restrict what is accepted", func(t *testing.T) {
+		config := &Config{ApprovalPolicy: ApprovalPolicy{
+			Default: ApprovalRules{Deny: []string{`sudo`}},
+			Programs: map[string]ApprovalRules{
+				"aider": {Allow: []string{`Edit the files\?`}},
+			},
+		}}
+		matcher := config.ApprovalMatcher()
+
+		allowed, _ := matcher.Allows("aider --model x", "Edit the files? (Y)es/(N)o/(D)on't ask again")
+		assert.True(t, allowed)
+		allowed, reason := matcher.Allows("/usr/local/bin/aider", "Run shell command? (Y)es/(N)o/(D)on't ask again")
+		assert.False(t, allowed)
+		assert.Equal(t, "not matched by any allow rule", reason)
+		// Allow rules of other programs don't apply, deny rules of the default do.
+		allowed, _ = matcher.Allows("claude", "Run shell command?")
+		assert.True(t, allowed)
+		allowed, _ = matcher.Allows("claude", "sudo make install")
+		assert.False(t, allowed)
+	})
+
+	t.Run("nil matcher accepts everything", func(t *testing.T) {
+		var matcher *ApprovalMatcher
+		allowed, _ := matcher.Allows("claude", "rm -rf /")
+		assert.True(t, allowed)
+	})
+
+	t.Run("invalid policy accepts nothing", func(t *testing.T) {
+		config := &Config{ApprovalPolicy: ApprovalPolicy{Default: ApprovalRules{Deny: []string{`(`}}}}
+		assert.ErrorContains(t, config.ApprovalPolicy.Validate(), "approval_policy.default.deny[0]")
+		allowed, _ := config.ApprovalMatcher().Allows("claude", "go test ./...")
+		assert.False(t, allowed)
+	})
+}
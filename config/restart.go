@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultRestartBackoff is the delay before the second restart of a dead session if a project
+	// doesn't set one.
+	DefaultRestartBackoff = 5
+	// maxRestartBackoff caps the delay between restarts of a dead session.
+	maxRestartBackoff = 5 * time.Minute
+)
+
+// RestartPolicy controls whether the tmux sessions of a project's instances are restarted when they
+// die, e.g. because the program crashed.
+type RestartPolicy struct {
+	// MaxRetries is the number of restarts in a row after which a dead session is left alone. Zero
+	// disables restarts.
+	MaxRetries int `json:"max_retries"`
+	// Backoff is the delay (seconds) before the second restart, DefaultRestartBackoff if zero. It
+	// doubles with every further restart, up to five minutes. The first restart happens right away.
+	Backoff int `json:"backoff"`
+}
+
+// Enabled returns whether dead sessions are restarted at all. A nil policy disables restarts.
+func (p *RestartPolicy) Enabled() bool {
+	return p != nil && p.MaxRetries > 0
+}
+
+// Validate checks the policy for values the supervisor cannot work with.
+func (p RestartPolicy) Validate() error {
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("max_retries cannot be negative, got %d", p.MaxRetries)
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("backoff cannot be negative, got %d", p.Backoff)
+	}
+	return nil
+}
+
+// Delay returns how long to wait after the given number of restarts before restarting again.
+func (p *RestartPolicy) Delay(restarts int) time.Duration {
+	if restarts <= 0 {
+		return 0
+	}
+	backoff := time.Duration(p.Backoff) * time.Second
+	if p.Backoff == 0 {
+		backoff = DefaultRestartBackoff * time.Second
+	}
+	for i := 1; i < restarts && backoff < maxRestartBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRestartBackoff)
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartPolicy(t *testing.T) {
+	var disabled *RestartPolicy
+	assert.False(t, disabled.Enabled())
+	assert.False(t, (&RestartPolicy{MaxRetries: 0, Backoff: 5}).Enabled())
+
+	policy := &RestartPolicy{MaxRetries: 10, Backoff: 30}
+	assert.True(t, policy.Enabled())
+	assert.Equal(t, time.Duration(0), policy.Delay(0))
+	assert.Equal(t, 30*time.Second, policy.Delay(1))
+	assert.Equal(t, time.Minute, policy.Delay(2))
+	assert.Equal(t, 2*time.Minute, policy.Delay(3))
+	assert.Equal(t, 5*time.Minute, policy.Delay(5))
+	assert.Equal(t, 5*time.Minute, policy.Delay(100))
+
+	assert.Equal(t, DefaultRestartBackoff*time.Second, (&RestartPolicy{MaxRetries: 1}).Delay(1))
+
+	assert.NoError(t, policy.Validate())
+	assert.Error(t, RestartPolicy{MaxRetries: -1}.Validate())
+	assert.Error(t, RestartPolicy{Backoff: -1}.Validate())
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Actions a schedule can run.
+const (
+	// ScheduleCreate creates a new instance, sending it the prompt once it has started.
+	ScheduleCreate = "create"
+	// ScheduleSend sends the prompt to an existing instance.
+	ScheduleSend = "send"
+)
+
+// Schedule is an action the daemon runs periodically, e.g. creating an instance bumping dependencies
+// every weekday at 02:00, or sending /status to an instance every hour.
+type Schedule struct {
+	// Name identifies the schedule. Instances it creates are marked with it.
+	Name string `json:"name"`
+	// Cron is the cron expression of when the schedule runs, in local time, e.g. "0 2 * * 1-5".
+	Cron string `json:"cron"`
+	// Repo is the path of the repository of the project the schedule works on.
+	Repo string `json:"repo"`
+	// Action is ScheduleCreate or ScheduleSend.
+	Action string `json:"action"`
+	// Instance is the title of the instance to create or send the prompt to. If an instance with
+	// that title exists already, the created one gets the time appended.
+	Instance string `json:"instance"`
+	// Prompt is sent to the instance. It is optional when creating an instance.
+	Prompt string `json:"prompt,omitempty"`
+	// Program is the program to run in created instances, the default program if empty.
+	Program string `json:"program,omitempty"`
+	// AutoYes enables AutoYes mode for created instances.
+	AutoYes bool `json:"auto_yes,omitempty"`
+}
+
+// Validate checks the schedule for values the daemon cannot work with.
+func (s Schedule) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("schedule name cannot be empty")
+	}
+	var errs []error
+	if _, err := ParseCron(s.Cron); err != nil {
+		errs = append(errs, err)
+	}
+	if strings.TrimSpace(s.Repo) == "" {
+		errs = append(errs, fmt.Errorf("repo cannot be empty"))
+	}
+	switch s.Action {
+	case ScheduleCreate:
+		if len(s.Instance) > 32 {
+			errs = append(errs, fmt.Errorf("instance cannot be longer than 32 characters"))
+		}
+	case ScheduleSend:
+		if strings.TrimSpace(s.Prompt) == "" {
+			errs = append(errs, fmt.Errorf("prompt is required to send a prompt"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("action must be %q or %q, got %q", ScheduleCreate, ScheduleSend, s.Action))
+	}
+	if strings.TrimSpace(s.Instance) == "" {
+		errs = append(errs, fmt.Errorf("instance cannot be empty"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("schedule %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// validateSchedules checks every schedule and that their names are unique.
+func validateSchedules(schedules []Schedule) error {
+	var errs []error
+	seen := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		if err := schedule.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if seen[schedule.Name] {
+			errs = append(errs, fmt.Errorf("schedule name %q is used more than once", schedule.Name))
+		}
+		seen[schedule.Name] = true
+	}
+	return errors.Join(errs...)
+}
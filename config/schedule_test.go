@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Thursday.
+	start := time.Date(2025, 1, 2, 10, 30, 15, 0, time.Local)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 2, 10, 31, 0, 0, time.Local)},
+		{"0 * * * *", time.Date(2025, 1, 2, 11, 0, 0, 0, time.Local)},
+		{"@hourly", time.Date(2025, 1, 2, 11, 0, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2025, 1, 2, 10, 45, 0, 0, time.Local)},
+		{"0 2 * * *", time.Date(2025, 1, 3, 2, 0, 0, 0, time.Local)},
+		{"0 2 * * 1-5", time.Date(2025, 1, 3, 2, 0, 0, 0, time.Local)},
+		{"0 2 * * sat,sun", time.Date(2025, 1, 4, 2, 0, 0, 0, time.Local)},
+		{"0 0 * * 7", time.Date(2025, 1, 5, 0, 0, 0, 0, time.Local)},
+		{"@weekly", time.Date(2025, 1, 5, 0, 0, 0, 0, time.Local)},
+		{"0 0 1 * *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"0 9 29 feb *", time.Date(2028, 2, 29, 9, 0, 0, 0, time.Local)},
+		// If both day fields are restricted, either matches: the 15th or a Monday.
+		{"0 0 15 * 1", time.Date(2025, 1, 6, 0, 0, 0, 0, time.Local)},
+		{"5/20 10 * * *", time.Date(2025, 1, 2, 10, 45, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(start))
+		})
+	}
+
+	never, err := ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(start).IsZero())
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "x * * * *", "@often"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	valid := func() Schedule {
+		return Schedule{Name: "deps", Cron: "0 2 * * 1-5", Repo: "/repo", Action: ScheduleCreate, Instance: "deps-bump"}
+	}
+	assert.NoError(t, valid().Validate())
+
+	schedule := valid()
+	schedule.Cron = "0 2 * *"
+	assert.ErrorContains(t, schedule.Validate(), "expected 5 fields")
+
+	schedule = valid()
+	schedule.Action = "delete"
+	assert.ErrorContains(t, schedule.Validate(), "action must be")
+
+	schedule = valid()
+	schedule.Action = ScheduleSend
+	assert.ErrorContains(t, schedule.Validate(), "prompt is required")
+	schedule.Prompt = "/status"
+	assert.NoError(t, schedule.Validate())
+
+	schedule = valid()
+	schedule.Instance = ""
+	assert.ErrorContains(t, schedule.Validate(), "instance cannot be empty")
+
+	assert.ErrorContains(t, validateSchedules([]Schedule{valid(), valid()}), "used more than once")
+}
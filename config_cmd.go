@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"claude-squad/log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Read, change and validate the config",
+		Long: "Read, change and validate the config file. Keys are the JSON keys of the config file, " +
+			"nested keys are separated by dots, e.g. 'llm.model'.",
+	}
+
+	configGetCmd = &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a config key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			cfg, err := readConfigOrDefault()
+			if err != nil {
+				return err
+			}
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		},
+	}
+
+	configSetCmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change the value of a config key",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			cfg, err := readConfigOrDefault()
+			if err != nil {
+				return err
+			}
+			if err := cfg.Set(args[0], args[1]); err != nil {
+				return err
+			}
+			if err := cfg.Validate(); err != nil {
+				return fmt.Errorf("config not saved: %w", err)
+			}
+			if err := config.SaveConfig(cfg); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	configEditCmd = &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config in $EDITOR and validate it before saving",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			configPath, err := config.GetConfigPath()
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(configPath)
+			if errors.Is(err, os.ErrNotExist) {
+				content, err = json.MarshalIndent(config.DefaultConfig(), "", "  ")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read config: %w", err)
+			}
+
+			// Edit a copy so that the config is never left in an invalid state.
+			tmp, err := os.CreateTemp("", "claude-squad-config-*.json")
+			if err != nil {
+				return fmt.Errorf("failed to create temporary file: %w", err)
+			}
+			defer os.Remove(tmp.Name())
+			if _, err := tmp.Write(content); err != nil {
+				tmp.Close()
+				return fmt.Errorf("failed to write temporary file: %w", err)
+			}
+			if err := tmp.Close(); err != nil {
+				return fmt.Errorf("failed to write temporary file: %w", err)
+			}
+
+			stdin := bufio.NewReader(os.Stdin)
+			for {
+				if err := runEditor(tmp.Name()); err != nil {
+					return err
+				}
+				edited, err := os.ReadFile(tmp.Name())
+				if err != nil {
+					return fmt.Errorf("failed to read edited config: %w", err)
+				}
+				_, err = config.ParseConfig(edited)
+				if err == nil {
+					if err := os.WriteFile(configPath, edited, 0644); err != nil {
+						return fmt.Errorf("failed to save config: %w", err)
+					}
+					fmt.Println("Config saved")
+					return nil
+				}
+
+				fmt.Printf("Invalid config: %v\nEdit again? [Y/n] ", err)
+				answer, _ := stdin.ReadString('\n')
+				if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "n" || answer == "no" {
+					return fmt.Errorf("config not saved")
+				}
+			}
+		},
+	}
+
+	configValidateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file for syntax errors, unknown keys and invalid values",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			configPath, err := config.GetConfigPath()
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(configPath)
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("%s does not exist, the defaults are used\n", configPath)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read config: %w", err)
+			}
+			if _, err := config.ParseConfig(content); err != nil {
+				return fmt.Errorf("%s is invalid: %w", configPath, err)
+			}
+			fmt.Printf("%s is valid\n", configPath)
+			return nil
+		},
+	}
+)
+
+// readConfigOrDefault reads the config file, or returns the default config if there is none. Unlike
+// config.LoadConfig, it fails on a broken config file instead of falling back to the defaults.
+func readConfigOrDefault() (*config.Config, error) {
+	cfg, err := config.ReadConfig()
+	if errors.Is(err, os.ErrNotExist) {
+		return config.DefaultConfig(), nil
+	}
+	return cfg, err
+}
+
+// runEditor opens path in the user's editor and waits for it to exit.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may come with arguments, e.g. "code --wait".
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
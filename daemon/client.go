@@ -0,0 +1,216 @@
+package daemon
+
+import (
+	"bufio"
+	"claude-squad/session"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// dialTimeout bounds how long connecting to the control socket may take.
+const dialTimeout = time.Second
+
+// Client talks to the daemon over its control socket. A Client must not be used concurrently.
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	encoder *json.Encoder
+	nextID  int64
+	// events holds notifications received while waiting for a response.
+	events []Event
+}
+
+// Dial connects to the daemon. It fails if no daemon is listening.
+func Dial() (*Client, error) {
+	socketPath, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	return dial(socketPath)
+}
+
+func dial(socketPath string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &Client{conn: conn, scanner: scanner, encoder: json.NewEncoder(conn)}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// call sends a request and decodes the result of the response into result, unless it is nil.
+func (c *Client) call(method string, params any, result any) error {
+	c.nextID++
+	id := c.nextID
+	req := request{JSONRPC: jsonRPCVersion, ID: &id, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal params: %w", err)
+		}
+		req.Params = raw
+	}
+	if err := c.encoder.Encode(req); err != nil {
+		return fmt.Errorf("failed to send request to daemon: %w", err)
+	}
+
+	for {
+		resp, err := c.read()
+		if err != nil {
+			return err
+		}
+		if resp.Method == notificationEvent {
+			var event Event
+			if err := json.Unmarshal(resp.Params, &event); err != nil {
+				return fmt.Errorf("failed to parse event: %w", err)
+			}
+			c.events = append(c.events, event)
+			continue
+		}
+		if resp.ID == nil || *resp.ID != id {
+			continue
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("failed to parse response of daemon: %w", err)
+		}
+		return nil
+	}
+}
+
+// read reads the next message from the daemon.
+func (c *Client) read() (*response, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read from daemon: %w", err)
+		}
+		return nil, fmt.Errorf("daemon closed the connection")
+	}
+	var resp response
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse message from daemon: %w", err)
+	}
+	return &resp, nil
+}
+
+// Ping checks that the daemon is responsive.
+func (c *Client) Ping() (*PingResult, error) {
+	var result PingResult
+	if err := c.call(MethodPing, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// List returns the instances of a project, or of all projects if projectID is empty. Unlike the
+// stored data, the status of running instances is up to date.
+func (c *Client) List(projectID string) ([]session.InstanceData, error) {
+	var instances []session.InstanceData
+	if err := c.call(MethodList, ListParams{ProjectID: projectID}, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// Status returns an instance.
+func (c *Client) Status(projectID, title string) (*session.InstanceData, error) {
+	var data session.InstanceData
+	if err := c.call(MethodStatus, InstanceRef{ProjectID: projectID, Title: title}, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Send sends a prompt to a running instance. It returns whether the daemon resumed the instance
+// first, since it was paused for being idle and idle_pause.auto_resume is set.
+func (c *Client) Send(projectID, title, prompt string) (bool, error) {
+	var result SendResult
+	if err := c.call(MethodSend, SendParams{InstanceRef: InstanceRef{ProjectID: projectID, Title: title}, Prompt: prompt}, &result); err != nil {
+		return false, err
+	}
+	return result.Resumed, nil
+}
+
+// Pause pauses an instance.
+func (c *Client) Pause(projectID, title string) (*session.InstanceData, error) {
+	var data session.InstanceData
+	if err := c.call(MethodPause, InstanceRef{ProjectID: projectID, Title: title}, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Resume resumes a paused instance.
+func (c *Client) Resume(projectID, title string) (*session.InstanceData, error) {
+	var data session.InstanceData
+	if err := c.call(MethodResume, InstanceRef{ProjectID: projectID, Title: title}, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Kill kills an instance and removes its worktree and branch.
+func (c *Client) Kill(projectID, title string) error {
+	return c.call(MethodKill, InstanceRef{ProjectID: projectID, Title: title}, nil)
+}
+
+// Create creates and starts an instance.
+func (c *Client) Create(params CreateParams) (*session.InstanceData, error) {
+	var data session.InstanceData
+	if err := c.call(MethodCreate, params, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Reload makes the daemon read the config file again. An invalid config is rejected.
+func (c *Client) Reload() error {
+	return c.call(MethodReload, nil, nil)
+}
+
+// Subscribe returns the current instances of all projects and makes the daemon stream changes to
+// them, which are read with NextEvent.
+func (c *Client) Subscribe() ([]session.InstanceData, error) {
+	var instances []session.InstanceData
+	if err := c.call(MethodSubscribe, nil, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// NextEvent blocks until the daemon reports a change. It requires a prior call to Subscribe. If the
+// client falls behind, an EventResync with all instances takes the place of the missed events.
+func (c *Client) NextEvent() (Event, error) {
+	if len(c.events) > 0 {
+		event := c.events[0]
+		c.events = c.events[1:]
+		return event, nil
+	}
+	for {
+		resp, err := c.read()
+		if err != nil {
+			return Event{}, err
+		}
+		if resp.Method != notificationEvent {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(resp.Params, &event); err != nil {
+			return Event{}, fmt.Errorf("failed to parse event: %w", err)
+		}
+		return event, nil
+	}
+}
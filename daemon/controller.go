@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+)
+
+// Controller performs operations on instances of a project. If the daemon is running, the operations
+// are sent to it, so that it and every other frontend sees them right away. Otherwise they are done
+// in this process.
+type Controller struct {
+	projectManager *session.ProjectInstanceManager
+	// client is nil if the daemon is not running.
+	client *Client
+}
+
+// NewController returns a controller for the instances of the project, connected to the daemon if
+// it is running.
+func NewController(projectManager *session.ProjectInstanceManager) *Controller {
+	client, err := Dial()
+	if err != nil {
+		log.InfoLog.Printf("daemon not reachable, working without it: %v", err)
+		client = nil
+	}
+	return &Controller{projectManager: projectManager, client: client}
+}
+
+// Connected reports whether operations are sent to the daemon.
+func (c *Controller) Connected() bool {
+	return c.client != nil
+}
+
+// Disconnect closes the connection to the daemon, if any, so that further operations are done in
+// this process, e.g. after the daemon stopped.
+func (c *Controller) Disconnect() {
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+}
+
+// Close closes the connection to the daemon, if any.
+func (c *Controller) Close() {
+	c.Disconnect()
+}
+
+// Pause pauses an instance and returns it in its new state. If the daemon paused it, that is a new
+// instance without a PTY, the given one is left as it was.
+func (c *Controller) Pause(instance *session.Instance) (*session.Instance, error) {
+	if c.client != nil {
+		data, err := c.client.Pause(c.projectManager.GetProjectID(), instance.Title)
+		if err != nil {
+			return nil, err
+		}
+		return session.FromInstanceDataDetached(*data), nil
+	}
+	return instance, c.projectManager.PauseInstance(instance)
+}
+
+// Resume resumes a paused instance and returns it in its new state, like Pause.
+func (c *Controller) Resume(instance *session.Instance) (*session.Instance, error) {
+	if c.client != nil {
+		data, err := c.client.Resume(c.projectManager.GetProjectID(), instance.Title)
+		if err != nil {
+			return nil, err
+		}
+		return session.FromInstanceDataDetached(*data), nil
+	}
+	return instance, c.projectManager.ResumeInstance(instance)
+}
+
+// Kill kills an instance and removes its worktree and branch.
+func (c *Controller) Kill(instance *session.Instance) error {
+	if c.client != nil {
+		return c.client.Kill(c.projectManager.GetProjectID(), instance.Title)
+	}
+	return c.projectManager.KillInstance(instance)
+}
+
+// Send sends a prompt to an instance. An instance which was paused for being idle is resumed first
+// if idle_pause.auto_resume is set. It returns whether the instance was resumed.
+func (c *Controller) Send(instance *session.Instance, prompt string) (bool, error) {
+	if c.client != nil {
+		return c.client.Send(c.projectManager.GetProjectID(), instance.Title, prompt)
+	}
+	return c.projectManager.SendPrompt(instance, prompt, config.LoadConfig().IdlePause.AutoResume)
+}
+
+// Create creates and starts an instance. If the daemon created it, the instance has no PTY.
+func (c *Controller) Create(opts session.InstanceOptions) (*session.Instance, error) {
+	if c.client != nil {
+		data, err := c.client.Create(CreateParams{
+			RepoPath:    c.projectManager.GetRepoPath(),
+			Title:       opts.Title,
+			DisplayName: opts.DisplayName,
+			Program:     opts.Program,
+			AutoYes:     opts.AutoYes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return session.FromInstanceDataDetached(*data), nil
+	}
+	return c.projectManager.CreateInstance(opts)
+}
@@ -10,54 +10,72 @@ import (
 	"os/signal"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
-// RunDaemon runs the daemon process which iterates over all sessions and runs AutoYes mode on them.
-// It's expected that the main process kills the daemon when the main process starts.
+// RunDaemon runs the daemon process. It watches the instances of all projects, runs AutoYes mode on
+// the ones which have it enabled, runs the schedules of the config and serves the control socket,
+// until it receives SIGINT or SIGTERM. SIGHUP makes it reload the config.
 func RunDaemon(cfg *config.Config) error {
 	log.InfoLog.Printf("starting daemon")
-	state := config.LoadState()
-	storage, err := session.NewStorage(state)
+	session.SetAuditSource("daemon")
+	configDir, err := config.GetConfigDir()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return fmt.Errorf("failed to get config directory: %w", err)
 	}
-
-	instances, err := storage.LoadInstances()
+	socketPath, err := SocketPath()
 	if err != nil {
-		return fmt.Errorf("failed to load instacnes: %w", err)
+		return err
 	}
-	for _, instance := range instances {
-		// Assume AutoYes is true if the daemon is running.
-		instance.AutoYes = true
+	// Failing would make systemd restart a daemon of the unit over and over while another daemon,
+	// e.g. one the UI launched before the unit was installed, serves the socket.
+	if client, err := dial(socketPath); err == nil {
+		client.Close()
+		log.InfoLog.Printf("another daemon is already serving %s, exiting", socketPath)
+		return nil
 	}
 
-	pollInterval := time.Duration(cfg.DaemonPollInterval) * time.Millisecond
+	w := newWatcher(configDir, cfg.ApprovalMatcher(), cfg.IdlePause.Duration())
+	w.autoResume = cfg.IdlePause.AutoResume
+	w.mu.Lock()
+	err = w.rescan()
+	log.InfoLog.Printf("daemon watching %d instances", len(w.instances))
+	w.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to load instances: %w", err)
+	}
 
-	// If we get an error for a session, it's likely that we'll keep getting the error. Log every 30 seconds.
-	everyN := log.NewEvery(60 * time.Second)
+	listener, err := listen(socketPath)
+	if err != nil {
+		return err
+	}
+	// The daemon writes the PID file itself, so that it is right however the daemon was started.
+	if err := writePIDFile(); err != nil {
+		listener.Close()
+		return err
+	}
+	defer removePIDFile()
+	sched := newScheduler(w, cfg.Schedules, cfg.DefaultProgram)
+	// The poll interval changes when the config is reloaded.
+	var pollInterval atomic.Int64
+	pollInterval.Store(int64(time.Duration(cfg.DaemonPollInterval) * time.Millisecond))
+	reload := func() error {
+		return reloadConfig(w, sched, &pollInterval)
+	}
+	srv := &server{watcher: w, scheduler: sched, reload: reload, startedAt: time.Now()}
+	go srv.serve(listener)
 
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 	stopCh := make(chan struct{})
 	go func() {
 		defer wg.Done()
-		ticker := time.NewTimer(pollInterval)
+		ticker := time.NewTimer(time.Duration(pollInterval.Load()))
 		for {
-			for _, instance := range instances {
-				// We only store started instances, but check anyway.
-				if instance.Started() && !instance.Paused() {
-					if _, hasPrompt := instance.HasUpdated(); hasPrompt {
-						instance.TapEnter()
-						if err := instance.UpdateDiffStats(); err != nil {
-							if everyN.ShouldLog() {
-								log.WarningLog.Printf("could not update diff stats for %s: %v", instance.Title, err)
-							}
-						}
-					}
-				}
-			}
+			w.poll()
+			sched.tick()
 
 			// Handle stop before ticker.
 			select {
@@ -67,26 +85,134 @@ func RunDaemon(cfg *config.Config) error {
 			}
 
 			<-ticker.C
-			ticker.Reset(pollInterval)
+			ticker.Reset(time.Duration(pollInterval.Load()))
 		}
 	}()
 
 	// Notify on SIGINT (Ctrl+C) and SIGTERM. Save instances before
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-sigChan
-	log.InfoLog.Printf("received signal %s", sig.String())
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sigChan {
+		log.InfoLog.Printf("received signal %s", sig.String())
+		if sig != syscall.SIGHUP {
+			break
+		}
+		if err := reload(); err != nil {
+			log.ErrorLog.Printf("failed to reload config: %v", err)
+		}
+	}
 
-	// Stop the goroutine so we don't race.
+	// Stop accepting requests and the goroutine so we don't race.
+	listener.Close()
 	close(stopCh)
 	wg.Wait()
 
-	if err := storage.SaveInstances(instances); err != nil {
-		log.ErrorLog.Printf("failed to save instances when terminating daemon: %v", err)
+	w.save()
+	w.releaseLeases()
+	return nil
+}
+
+// reloadMu serializes reloads, which may be triggered by SIGHUP and by clients at the same time.
+var reloadMu sync.Mutex
+
+// reloadConfig reads the config file again and applies it to the running daemon: the poll interval,
+// the approval policy, idle pausing and the schedules. Instances and projects created meanwhile are
+// picked up, instances which are already watched keep their state, e.g. the output they last showed.
+// An invalid config is rejected and the daemon keeps running with the previous one.
+func reloadConfig(w *watcher, sched *scheduler, pollInterval *atomic.Int64) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return err
 	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config, keeping the previous one: %w", err)
+	}
+
+	pollInterval.Store(int64(time.Duration(cfg.DaemonPollInterval) * time.Millisecond))
+	sched.update(cfg.Schedules, cfg.DefaultProgram)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.approvals = cfg.ApprovalMatcher()
+	w.idlePause = cfg.IdlePause.Duration()
+	w.autoResume = cfg.IdlePause.AutoResume
+	if err := w.rescan(); err != nil {
+		return fmt.Errorf("failed to reload instances: %w", err)
+	}
+	log.InfoLog.Printf("reloaded config, watching %d instances", len(w.instances))
 	return nil
 }
 
+// daemonStartTimeout is how long EnsureRunning waits for a launched daemon to serve its socket.
+const daemonStartTimeout = 5 * time.Second
+
+// Running reports whether a daemon is serving the control socket.
+func Running() bool {
+	client, err := Dial()
+	if err != nil {
+		return false
+	}
+	defer client.Close()
+	_, err = client.Ping()
+	return err == nil
+}
+
+// EnsureRunning launches the daemon unless it is running already, and waits until it serves the
+// control socket. A daemon process which does not serve the socket is stopped first. If the systemd
+// user unit is installed, the daemon is started through systemd, so that it stays under its
+// supervision.
+func EnsureRunning() error {
+	if Running() {
+		return nil
+	}
+	if err := StopDaemon(); err != nil {
+		log.WarningLog.Printf("failed to stop unresponsive daemon: %v", err)
+	}
+	if err := startDaemon(); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(daemonStartTimeout)
+	for time.Now().Before(deadline) {
+		if Running() {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("daemon did not start serving its socket within %s", daemonStartTimeout)
+}
+
+// RestartDaemon stops the daemon if it is running and starts it again, through systemd if the user
+// unit is installed.
+func RestartDaemon() error {
+	if ServiceInstalled() {
+		// Stopping the unit keeps systemd from restarting the daemon behind our back. A daemon which
+		// was not started by systemd is stopped below.
+		if err := systemctl("stop", ServiceName); err != nil {
+			log.WarningLog.Printf("failed to stop %s: %v", ServiceName, err)
+		}
+	}
+	if err := StopDaemon(); err != nil {
+		return err
+	}
+	return EnsureRunning()
+}
+
+// startDaemon starts the daemon through systemd if its user unit is installed, and launches the
+// process directly otherwise or if systemd fails to start it.
+func startDaemon() error {
+	if ServiceInstalled() {
+		err := systemctl("start", ServiceName)
+		if err == nil {
+			return nil
+		}
+		log.WarningLog.Printf("failed to start %s, launching the daemon directly: %v", ServiceName, err)
+	}
+	return LaunchDaemon()
+}
+
 // LaunchDaemon launches the daemon process.
 func LaunchDaemon() error {
 	// Find the claude squad binary.
@@ -95,7 +221,7 @@ func LaunchDaemon() error {
 		return fmt.Errorf("failed to get executable path: %w", err)
 	}
 
-	cmd := exec.Command(execPath, "--daemon")
+	cmd := exec.Command(execPath, "daemon", "run")
 
 	// Detach the process from the parent
 	cmd.Stdin = nil
@@ -111,57 +237,117 @@ func LaunchDaemon() error {
 
 	log.InfoLog.Printf("started daemon child process with PID: %d", cmd.Process.Pid)
 
-	// Save PID to a file for later management
-	pidDir, err := config.GetConfigDir()
+	// Don't wait for the child to exit, it's detached
+	return nil
+}
+
+// stopTimeout is how long StopDaemon waits for the daemon to shut down before killing it.
+const stopTimeout = 10 * time.Second
+
+// StopDaemon asks a running daemon to shut down and waits for it to exit. It returns no error if
+// the daemon is not running. A PID file naming a process which is not the daemon is removed without
+// touching the process.
+func StopDaemon() error {
+	status, err := GetStatus()
 	if err != nil {
-		return fmt.Errorf("failed to get config directory: %w", err)
+		return err
+	}
+	if status.StalePIDFile {
+		log.InfoLog.Printf("removing stale daemon PID file naming process %d", status.PID)
+		if err := os.Remove(status.PIDFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove PID file: %w", err)
+		}
+	}
+	if !status.Running {
+		return nil
 	}
 
-	pidFile := filepath.Join(pidDir, "daemon.pid")
-	if err := os.WriteFile(pidFile, []byte(fmt.Sprintf("%d", cmd.Process.Pid)), 0644); err != nil {
-		return fmt.Errorf("failed to write PID file: %w", err)
+	// SIGTERM lets the daemon save the instances before it exits.
+	if err := terminateProcess(status.PID); err != nil {
+		return fmt.Errorf("failed to stop daemon process: %w", err)
+	}
+	deadline := time.Now().Add(stopTimeout)
+	for ProcessAlive(status.PID) {
+		if time.Now().After(deadline) {
+			log.WarningLog.Printf("daemon process (PID: %d) did not exit within %s, killing it", status.PID, stopTimeout)
+			proc, err := os.FindProcess(status.PID)
+			if err != nil {
+				return fmt.Errorf("failed to find daemon process: %w", err)
+			}
+			if err := proc.Kill(); err != nil {
+				return fmt.Errorf("failed to kill daemon process: %w", err)
+			}
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
 	}
 
-	// Don't wait for the child to exit, it's detached
+	// The daemon removes the PID file when it exits, but not when it was killed.
+	if err := os.Remove(status.PIDFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove PID file: %w", err)
+	}
+
+	log.InfoLog.Printf("daemon process (PID: %d) stopped successfully", status.PID)
 	return nil
 }
 
-// StopDaemon attempts to stop a running daemon process if it exists. Returns no error if the daemon is not found
-// (assumes the daemon does not exist).
-func StopDaemon() error {
-	pidDir, err := config.GetConfigDir()
+// writePIDFile records the PID of the current process as the daemon's.
+func writePIDFile() error {
+	pidFile, err := PIDFilePath()
 	if err != nil {
-		return fmt.Errorf("failed to get config directory: %w", err)
+		return err
 	}
+	if err := os.WriteFile(pidFile, []byte(fmt.Sprintf("%d", os.Getpid())), 0644); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
 
-	pidFile := filepath.Join(pidDir, "daemon.pid")
-	data, err := os.ReadFile(pidFile)
+// removePIDFile removes the PID file, unless another daemon has replaced it meanwhile.
+func removePIDFile() {
+	if pid, err := ReadPID(); err != nil || pid != os.Getpid() {
+		return
+	}
+	pidFile, err := PIDFilePath()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("failed to read PID file: %w", err)
+		return
 	}
-
-	var pid int
-	if _, err := fmt.Sscanf(string(data), "%d", &pid); err != nil {
-		return fmt.Errorf("invalid PID file format: %w", err)
+	if err := os.Remove(pidFile); err != nil && !os.IsNotExist(err) {
+		log.WarningLog.Printf("failed to remove PID file: %v", err)
 	}
+}
 
-	proc, err := os.FindProcess(pid)
+// PIDFilePath returns the path of the file the PID of the daemon is written to.
+func PIDFilePath() (string, error) {
+	pidDir, err := config.GetConfigDir()
 	if err != nil {
-		return fmt.Errorf("failed to find daemon process: %w", err)
+		return "", fmt.Errorf("failed to get config directory: %w", err)
 	}
+	return filepath.Join(pidDir, "daemon.pid"), nil
+}
 
-	if err := proc.Kill(); err != nil {
-		return fmt.Errorf("failed to stop daemon process: %w", err)
+// ReadPID returns the PID recorded in the PID file, or 0 if there is no PID file.
+func ReadPID() (int, error) {
+	pidFile, err := PIDFilePath()
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
 	}
 
-	// Clean up PID file
-	if err := os.Remove(pidFile); err != nil {
-		return fmt.Errorf("failed to remove PID file: %w", err)
+	var pid int
+	if _, err := fmt.Sscanf(string(data), "%d", &pid); err != nil {
+		return 0, fmt.Errorf("invalid PID file format: %w", err)
 	}
+	return pid, nil
+}
 
-	log.InfoLog.Printf("daemon process (PID: %d) stopped successfully", pid)
-	return nil
+// ProcessAlive reports whether a process with the given PID is running.
+func ProcessAlive(pid int) bool {
+	return pid > 0 && processAlive(pid)
 }
@@ -0,0 +1,42 @@
+//go:build linux
+
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// isDaemonProcess checks in /proc whether pid is a daemon process of claude-squad: it must run an
+// executable with the same name as this one, with the arguments of the daemon.
+func isDaemonProcess(pid int) (bool, error) {
+	procDir := filepath.Join("/proc", strconv.Itoa(pid))
+	cmdline, err := os.ReadFile(filepath.Join(procDir, "cmdline"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read command line of process %d: %w", pid, err)
+	}
+	args := strings.Split(string(bytes.TrimRight(cmdline, "\x00")), "\x00")
+	if !isDaemonArgs(args) {
+		return false, nil
+	}
+
+	exe, err := os.Readlink(filepath.Join(procDir, "exe"))
+	if err != nil {
+		// The link of processes of other users can't be read. Their arguments have to do.
+		return true, nil
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	// The executable of a daemon which was started before an upgrade is shown as deleted.
+	exe = strings.TrimSuffix(exe, " (deleted)")
+	return filepath.Base(exe) == filepath.Base(self), nil
+}
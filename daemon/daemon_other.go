@@ -0,0 +1,9 @@
+//go:build !linux
+
+package daemon
+
+// isDaemonProcess can't inspect other processes on this platform, so the caller has to tell the
+// daemon apart by other means.
+func isDaemonProcess(pid int) (bool, error) {
+	return false, errIdentityUnknown
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".claude-squad")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	writeConfig := func(content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.json"), []byte(content), 0644))
+	}
+
+	w := newWatcher(configDir, nil, 0)
+	sched := newScheduler(w, nil, "claude")
+	var pollInterval atomic.Int64
+	pollInterval.Store(int64(time.Second))
+
+	writeConfig(`{"default_program": "aider", "daemon_poll_interval": 250, "idle_pause": {"after": 5, "auto_resume": true},
+		"schedules": [{"name": "status", "cron": "@hourly", "repo": "/repo", "action": "send",
+		"instance": "foo", "prompt": "/status"}]}`)
+	require.NoError(t, reloadConfig(w, sched, &pollInterval))
+	assert.Equal(t, 250*time.Millisecond, time.Duration(pollInterval.Load()))
+	assert.Equal(t, 5*time.Minute, w.idlePause)
+	assert.True(t, w.autoResume)
+	assert.NotNil(t, w.approvals)
+	require.Len(t, sched.statuses(), 1)
+	assert.Equal(t, "aider", sched.defaultProgram)
+
+	// An invalid config is rejected as a whole.
+	writeConfig(`{"default_program": "aider", "daemon_poll_interval": 0}`)
+	assert.Error(t, reloadConfig(w, sched, &pollInterval))
+	assert.Equal(t, 250*time.Millisecond, time.Duration(pollInterval.Load()))
+	assert.Len(t, sched.statuses(), 1)
+}
+
+func TestRunDaemonExitsIfAnotherDaemonServesTheSocket(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".claude-squad")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+
+	listener, err := listen(filepath.Join(configDir, "daemon.sock"))
+	require.NoError(t, err)
+	defer listener.Close()
+	go (&server{watcher: newWatcher(configDir, nil, 0), startedAt: time.Now()}).serve(listener)
+
+	// Exiting successfully keeps systemd from restarting the daemon of the unit over and over.
+	assert.NoError(t, RunDaemon(&config.Config{DaemonPollInterval: 1000}))
+	assert.NoFileExists(t, filepath.Join(configDir, "daemon.pid"))
+}
@@ -3,6 +3,8 @@
 package daemon
 
 import (
+	"errors"
+	"net"
 	"syscall"
 )
 
@@ -12,3 +14,24 @@ func getSysProcAttr() *syscall.SysProcAttr {
 		Setsid: true, // Create a new session
 	}
 }
+
+// processAlive checks for the process by sending signal 0. EPERM means it exists but belongs to
+// another user.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminateProcess asks the process to shut down.
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// listenPrivate listens on a Unix socket only the current user can connect to. The socket is created
+// with these permissions rather than restricted afterwards, so other users never get to connect. The
+// umask is per process, so this must not run while other goroutines create files.
+func listenPrivate(socketPath string) (net.Listener, error) {
+	umask := syscall.Umask(0177)
+	defer syscall.Umask(umask)
+	return net.Listen("unix", socketPath)
+}
@@ -4,6 +4,8 @@ package daemon
 
 import (
 	"golang.org/x/sys/windows"
+	"net"
+	"os"
 	"syscall"
 )
 
@@ -13,3 +15,36 @@ func getSysProcAttr() *syscall.SysProcAttr {
 		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
 	}
 }
+
+// stillActive is the exit code GetExitCodeProcess reports for running processes.
+const stillActive = 259
+
+// processAlive checks whether the process exists and has not exited yet.
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// terminateProcess kills the process. Windows has no signal asking a process to shut down.
+func terminateProcess(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
+
+// listenPrivate listens on a Unix socket. Windows has no umask, the socket is protected by the
+// permissions of the config directory.
+func listenPrivate(socketPath string) (net.Listener, error) {
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// The daemon speaks JSON-RPC 2.0 on a Unix domain socket, one JSON object per line. Status events
+// of a subscription are sent as notifications of the method "event".
+
+// Methods of the daemon's API.
+const (
+	MethodPing      = "ping"
+	MethodList      = "list"
+	MethodStatus    = "status"
+	MethodSend      = "send"
+	MethodPause     = "pause"
+	MethodResume    = "resume"
+	MethodKill      = "kill"
+	MethodCreate    = "create"
+	MethodReload    = "reload"
+	MethodSubscribe = "subscribe"
+
+	notificationEvent = "event"
+)
+
+// Error codes of the daemon's API. The first ones are defined by JSON-RPC 2.0.
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	// ErrCodeFailed is returned when an operation on an instance fails.
+	ErrCodeFailed = 1
+	// ErrCodeNotFound is returned when the project or instance does not exist.
+	ErrCodeNotFound = 2
+)
+
+const jsonRPCVersion = "2.0"
+
+// request is a JSON-RPC request. Requests without ID are notifications and get no response.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response, or a notification if Method is set.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is an error returned by the daemon.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ListParams are the parameters of MethodList.
+type ListParams struct {
+	// ProjectID restricts the list to one project. All projects are listed if it is empty.
+	ProjectID string `json:"project_id,omitempty"`
+}
+
+// InstanceRef identifies an instance. It is the parameter of MethodStatus, MethodPause,
+// MethodResume and MethodKill.
+type InstanceRef struct {
+	ProjectID string `json:"project_id"`
+	Title     string `json:"title"`
+}
+
+// SendParams are the parameters of MethodSend.
+type SendParams struct {
+	InstanceRef
+	Prompt string `json:"prompt"`
+}
+
+// SendResult is the result of MethodSend.
+type SendResult struct {
+	// Resumed is set if the instance was resumed first, since it was paused for being idle.
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+// CreateParams are the parameters of MethodCreate. The project is created if it does not exist.
+type CreateParams struct {
+	RepoPath    string `json:"repo_path"`
+	Title       string `json:"title"`
+	DisplayName string `json:"display_name,omitempty"`
+	Program     string `json:"program"`
+	AutoYes     bool   `json:"auto_yes"`
+}
+
+// EventType is the kind of change an Event reports.
+type EventType string
+
+const (
+	// EventStatus is sent when an instance is created or its status changes.
+	EventStatus EventType = "status"
+	// EventRemoved is sent when an instance is killed.
+	EventRemoved EventType = "removed"
+	// EventResync is sent instead of the events a subscriber was too slow to receive. It carries
+	// all instances, which replace what the subscriber knows.
+	EventResync EventType = "resync"
+)
+
+// Event is a change of an instance, streamed to subscribers.
+type Event struct {
+	Type     EventType            `json:"type"`
+	Instance session.InstanceData `json:"instance"`
+	// Instances are the instances of all projects, sent with EventResync.
+	Instances []session.InstanceData `json:"instances,omitempty"`
+}
+
+// SocketPath returns the path of the daemon's control socket.
+func SocketPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "daemon.sock"), nil
+}
@@ -0,0 +1,221 @@
+package daemon
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ScheduleStatus describes a schedule of the daemon and the result of its last run.
+type ScheduleStatus struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// Next is when the schedule runs next, zero if never.
+	Next    time.Time `json:"next"`
+	LastRun time.Time `json:"last_run,omitempty"`
+	// LastInstance is the title of the instance the last run created or sent the prompt to.
+	LastInstance string `json:"last_instance,omitempty"`
+	// LastError is the error of the last run, empty if it succeeded.
+	LastError string `json:"last_error,omitempty"`
+	// Running is set while a run is in progress.
+	Running bool `json:"running,omitempty"`
+}
+
+// scheduleEntry is a schedule of the config together with its state.
+type scheduleEntry struct {
+	schedule config.Schedule
+	cron     *config.CronSchedule
+	status   ScheduleStatus
+}
+
+// scheduler runs the schedules of the config: it creates instances and sends prompts to instances
+// when their cron expressions say so. Runs which were due while the daemon was not running are
+// skipped. A schedule doesn't run again while its last run is still in progress.
+type scheduler struct {
+	watcher *watcher
+	now     func() time.Time
+
+	// mu guards entries and defaultProgram. It is never held while waiting for the watcher's mu.
+	mu      sync.Mutex
+	entries []*scheduleEntry
+	// defaultProgram is the program created instances run if their schedule doesn't set one.
+	defaultProgram string
+}
+
+// newScheduler returns a scheduler for the given schedules. Invalid schedules are logged and left
+// out.
+func newScheduler(w *watcher, schedules []config.Schedule, defaultProgram string) *scheduler {
+	s := &scheduler{watcher: w, now: time.Now}
+	s.update(schedules, defaultProgram)
+	return s
+}
+
+// update replaces the schedules, e.g. after the config was reloaded. Schedules which did not change
+// keep their state, changed and new ones run next at the next time their cron expression matches.
+// Invalid schedules are logged and left out.
+func (s *scheduler) update(schedules []config.Schedule, defaultProgram string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	previous := make(map[string]*scheduleEntry, len(s.entries))
+	for _, entry := range s.entries {
+		previous[entry.schedule.Name] = entry
+	}
+
+	entries := make([]*scheduleEntry, 0, len(schedules))
+	for _, schedule := range schedules {
+		if entry, ok := previous[schedule.Name]; ok && entry.schedule == schedule {
+			entries = append(entries, entry)
+			continue
+		}
+		if err := schedule.Validate(); err != nil {
+			log.ErrorLog.Printf("ignoring invalid schedule: %v", err)
+			continue
+		}
+		cron, err := config.ParseCron(schedule.Cron)
+		if err != nil {
+			log.ErrorLog.Printf("ignoring invalid schedule %s: %v", schedule.Name, err)
+			continue
+		}
+		entry := &scheduleEntry{schedule: schedule, cron: cron}
+		entry.status = ScheduleStatus{Name: schedule.Name, Action: schedule.Action, Next: cron.Next(now)}
+		entries = append(entries, entry)
+	}
+	s.entries = entries
+	s.defaultProgram = defaultProgram
+	if len(entries) > 0 {
+		log.InfoLog.Printf("daemon running %d schedules", len(entries))
+	}
+}
+
+// tick starts the runs of all schedules which are due. Runs happen in the background, since creating
+// an instance and waiting for it to start takes a while.
+func (s *scheduler) tick() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, entry := range s.entries {
+		if entry.status.Next.IsZero() || now.Before(entry.status.Next) {
+			continue
+		}
+		entry.status.Next = entry.cron.Next(now)
+		if entry.status.Running {
+			log.WarningLog.Printf("skipping run of schedule %s, the last one is still in progress", entry.schedule.Name)
+			continue
+		}
+		entry.status.Running = true
+		entry.status.LastRun = now
+		schedule := entry.schedule
+		if schedule.Program == "" {
+			schedule.Program = s.defaultProgram
+		}
+		go s.run(entry, schedule)
+	}
+}
+
+// run runs the action of a schedule and records the result.
+func (s *scheduler) run(entry *scheduleEntry, schedule config.Schedule) {
+	log.InfoLog.Printf("running schedule %s: %s %s in %s", schedule.Name, schedule.Action, schedule.Instance, schedule.Repo)
+	var title string
+	var err error
+	if schedule.Action == config.ScheduleCreate {
+		title, err = s.create(schedule)
+	} else {
+		title, err = s.send(schedule)
+	}
+	if err != nil {
+		log.ErrorLog.Printf("schedule %s failed: %v", schedule.Name, err)
+	} else {
+		log.InfoLog.Printf("schedule %s ran on instance %s", schedule.Name, title)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.status.Running = false
+	entry.status.LastInstance = title
+	entry.status.LastError = ""
+	if err != nil {
+		entry.status.LastError = err.Error()
+	}
+}
+
+// create creates the instance of a schedule and sends it the prompt once its program has started.
+// It returns the title of the instance, which has the time appended if the schedule's title is
+// taken.
+func (s *scheduler) create(schedule config.Schedule) (string, error) {
+	opts := session.InstanceOptions{
+		Program:     schedule.Program,
+		AutoYes:     schedule.AutoYes,
+		ScheduledBy: schedule.Name,
+	}
+	data, err := s.watcher.create(schedule.Repo, opts, func(taken map[string]bool) string {
+		return scheduledTitle(schedule.Instance, s.now(), taken)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if schedule.Prompt == "" {
+		return data.Title, nil
+	}
+	// Waiting for the program to start takes up to half a minute, so the prompt is sent through an
+	// instance of its own rather than the one the watcher polls.
+	if err := session.SendInitialPrompt(session.FromInstanceDataDetached(data), schedule.Prompt); err != nil {
+		return data.Title, fmt.Errorf("instance created but failed to send prompt: %w", err)
+	}
+	return data.Title, nil
+}
+
+// send sends the prompt of a schedule to its instance.
+func (s *scheduler) send(schedule config.Schedule) (string, error) {
+	w := s.watcher
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	projectManager, err := w.instanceManager.GetProjectManagerForPath(schedule.Repo)
+	if err != nil {
+		return schedule.Instance, err
+	}
+	tracked, err := w.lookup(InstanceRef{ProjectID: projectManager.GetProjectID(), Title: schedule.Instance})
+	if err != nil {
+		return schedule.Instance, err
+	}
+	_, err = w.sendPrompt(tracked, schedule.Prompt)
+	return schedule.Instance, err
+}
+
+// statuses returns the status of every schedule, ordered by name.
+func (s *scheduler) statuses() []ScheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]ScheduleStatus, 0, len(s.entries))
+	for _, entry := range s.entries {
+		statuses = append(statuses, entry.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// scheduledTitle returns the title of an instance created by a schedule. If the title is taken, e.g.
+// because the instance of the last run is still around, the time is appended.
+func scheduledTitle(title string, now time.Time, taken map[string]bool) string {
+	if !taken[title] {
+		return title
+	}
+	suffix := "-" + now.Format("0102-1504")
+	base := title
+	if len(base)+len(suffix) > session.MaxTitleLength {
+		base = base[:session.MaxTitleLength-len(suffix)]
+	}
+	candidate := base + suffix
+	for n := 2; taken[candidate]; n++ {
+		numbered := fmt.Sprintf("%s-%d", suffix, n)
+		candidate = base[:min(len(base), session.MaxTitleLength-len(numbered))] + numbered
+	}
+	return candidate
+}
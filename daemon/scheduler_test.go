@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"claude-squad/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledTitle(t *testing.T) {
+	now := time.Date(2025, 3, 4, 2, 0, 0, 0, time.Local)
+	assert.Equal(t, "deps-bump", scheduledTitle("deps-bump", now, map[string]bool{}))
+	assert.Equal(t, "deps-bump-0304-0200", scheduledTitle("deps-bump", now, map[string]bool{"deps-bump": true}))
+	assert.Equal(t, "deps-bump-0304-0200-2", scheduledTitle("deps-bump", now,
+		map[string]bool{"deps-bump": true, "deps-bump-0304-0200": true}))
+
+	long := strings.Repeat("a", 32)
+	title := scheduledTitle(long, now, map[string]bool{long: true})
+	assert.Len(t, title, 32)
+	assert.True(t, strings.HasSuffix(title, "-0304-0200"))
+}
+
+func TestSchedulerRecordsResults(t *testing.T) {
+	dir := t.TempDir()
+	w := newWatcher(dir, nil, 0)
+	schedules := []config.Schedule{
+		{Name: "status", Cron: "0 * * * *", Repo: dir, Action: config.ScheduleSend, Instance: "foo", Prompt: "/status"},
+		{Name: "broken", Cron: "every hour", Repo: dir, Action: config.ScheduleSend, Instance: "foo", Prompt: "/status"},
+	}
+	s := newScheduler(w, schedules, "claude")
+	start := time.Date(2025, 3, 4, 2, 30, 0, 0, time.Local)
+	s.now = func() time.Time { return start }
+	s.entries[0].status.Next = s.entries[0].cron.Next(start)
+
+	statuses := s.statuses()
+	require.Len(t, statuses, 1, "invalid schedules are left out")
+	assert.Equal(t, time.Date(2025, 3, 4, 3, 0, 0, 0, time.Local), statuses[0].Next)
+
+	// Nothing is due yet.
+	s.tick()
+	assert.True(t, s.statuses()[0].LastRun.IsZero())
+
+	s.now = func() time.Time { return start.Add(30 * time.Minute) }
+	s.tick()
+	require.Eventually(t, func() bool { return !s.statuses()[0].Running }, 5*time.Second, 10*time.Millisecond)
+	status := s.statuses()[0]
+	assert.Equal(t, start.Add(30*time.Minute), status.LastRun)
+	assert.Equal(t, time.Date(2025, 3, 4, 4, 0, 0, 0, time.Local), status.Next)
+	// The directory is not a git repository.
+	assert.NotEmpty(t, status.LastError)
+	assert.Equal(t, "foo", status.LastInstance)
+}
+
+func TestSchedulerUpdateKeepsUnchangedSchedules(t *testing.T) {
+	dir := t.TempDir()
+	w := newWatcher(dir, nil, 0)
+	status := config.Schedule{Name: "status", Cron: "@hourly", Repo: dir, Action: config.ScheduleSend, Instance: "foo", Prompt: "/status"}
+	deps := config.Schedule{Name: "deps", Cron: "0 2 * * *", Repo: dir, Action: config.ScheduleCreate, Instance: "deps-bump"}
+	s := newScheduler(w, []config.Schedule{status, deps}, "claude")
+	lastRun := time.Date(2025, 3, 4, 2, 0, 0, 0, time.Local)
+	for _, entry := range s.entries {
+		entry.status.LastRun = lastRun
+	}
+
+	deps.Cron = "0 3 * * *"
+	s.update([]config.Schedule{status, deps}, "claude")
+	statuses := s.statuses()
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].LastRun.IsZero(), "changed schedules start over")
+	assert.Equal(t, lastRun, statuses[1].LastRun)
+
+	s.update([]config.Schedule{deps}, "claude")
+	require.Len(t, s.statuses(), 1)
+	assert.Equal(t, "deps", s.statuses()[0].Name)
+}
@@ -0,0 +1,273 @@
+package daemon
+
+import (
+	"bufio"
+	"claude-squad/log"
+	"claude-squad/session"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// PingResult is the result of MethodPing.
+type PingResult struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	Instances int       `json:"instances"`
+	// AutoYes is the number of running instances whose prompts the daemon accepts.
+	AutoYes int `json:"auto_yes"`
+	// Schedules are the schedules the daemon runs.
+	Schedules []ScheduleStatus `json:"schedules,omitempty"`
+}
+
+// server serves the daemon's API on the control socket.
+type server struct {
+	watcher   *watcher
+	scheduler *scheduler
+	// reload reloads the config, nil if the daemon can't.
+	reload    func() error
+	startedAt time.Time
+}
+
+// listen creates the control socket. A socket left behind by a daemon which did not shut down
+// cleanly is replaced, one of a running daemon is not.
+func listen(socketPath string) (net.Listener, error) {
+	if client, err := dial(socketPath); err == nil {
+		client.Close()
+		return nil, fmt.Errorf("another daemon is already listening on %s", socketPath)
+	}
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	// The socket gives full control over all instances.
+	listener, err := listenPrivate(socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	return listener, nil
+}
+
+// serve accepts connections until the listener is closed.
+func (s *server) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.ErrorLog.Printf("failed to accept connection: %v", err)
+			}
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// connWriter writes responses and notifications to a connection. Writes are serialized since
+// events are streamed while requests are answered.
+type connWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func (w *connWriter) write(resp response) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	resp.JSONRPC = jsonRPCVersion
+	return w.encoder.Encode(resp)
+}
+
+func (s *server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	writer := &connWriter{encoder: json.NewEncoder(conn)}
+	done := make(chan struct{})
+	defer close(done)
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = writer.write(response{Error: &Error{Code: ErrCodeParse, Message: err.Error()}})
+			continue
+		}
+		if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+			_ = writer.write(response{ID: req.ID, Error: &Error{Code: ErrCodeInvalidRequest, Message: "invalid request"}})
+			continue
+		}
+
+		var result any
+		var err error
+		var events chan Event
+		if req.Method == MethodSubscribe {
+			result, events, err = s.subscribe()
+		} else {
+			result, err = s.call(req.Method, req.Params)
+		}
+		if req.ID != nil {
+			resp := response{ID: req.ID}
+			if err != nil {
+				var rpcErr *Error
+				if !errors.As(err, &rpcErr) {
+					rpcErr = &Error{Code: ErrCodeFailed, Message: err.Error()}
+				}
+				resp.Error = rpcErr
+			} else if resp.Result, err = json.Marshal(result); err != nil {
+				resp.Error = &Error{Code: ErrCodeFailed, Message: fmt.Sprintf("failed to marshal result: %v", err)}
+			}
+			if err := writer.write(resp); err != nil {
+				if events != nil {
+					s.watcher.unsubscribe(events)
+				}
+				return
+			}
+		}
+		if events != nil {
+			// Events are only streamed once the client got the instances they follow up on.
+			go s.stream(writer, events, done)
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+		log.WarningLog.Printf("failed to read from client: %v", err)
+	}
+}
+
+// subscribe returns the current instances and a channel receiving every later change. Both are
+// taken under the watcher's lock, so that no change is missed in between.
+func (s *server) subscribe() ([]session.InstanceData, chan Event, error) {
+	s.watcher.mu.Lock()
+	defer s.watcher.mu.Unlock()
+	instances, err := s.watcher.list("")
+	if err != nil {
+		return nil, nil, err
+	}
+	return instances, s.watcher.subscribe(), nil
+}
+
+// stream writes the events to the connection as notifications until done is closed.
+func (s *server) stream(writer *connWriter, events chan Event, done chan struct{}) {
+	defer s.watcher.unsubscribe(events)
+	for {
+		select {
+		case <-done:
+			return
+		case event := <-events:
+			params, err := json.Marshal(event)
+			if err != nil {
+				log.ErrorLog.Printf("failed to marshal event: %v", err)
+				continue
+			}
+			if err := writer.write(response{Method: notificationEvent, Params: params}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// call runs a method other than MethodSubscribe.
+func (s *server) call(method string, rawParams json.RawMessage) (any, error) {
+	decode := func(params any) error {
+		if len(rawParams) == 0 {
+			return &Error{Code: ErrCodeInvalidParams, Message: "missing params"}
+		}
+		if err := json.Unmarshal(rawParams, params); err != nil {
+			return &Error{Code: ErrCodeInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+
+	// Reloading takes the watcher's lock itself.
+	if method == MethodReload {
+		if s.reload == nil {
+			return nil, &Error{Code: ErrCodeFailed, Message: "reloading the config is not supported"}
+		}
+		return nil, s.reload()
+	}
+
+	// Creating an instance takes the watcher's lock itself, since it must not be held while the
+	// instance starts.
+	if method == MethodCreate {
+		var params CreateParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		opts := session.InstanceOptions{
+			DisplayName: params.DisplayName,
+			Program:     params.Program,
+			AutoYes:     params.AutoYes,
+		}
+		return s.watcher.create(params.RepoPath, opts, func(map[string]bool) string { return params.Title })
+	}
+
+	w := s.watcher
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch method {
+	case MethodPing:
+		result := PingResult{PID: os.Getpid(), StartedAt: s.startedAt, Instances: len(w.instances)}
+		for _, tracked := range w.instances {
+			if data := tracked.data(); data.AutoYes && data.Status != session.Paused {
+				result.AutoYes++
+			}
+		}
+		if s.scheduler != nil {
+			result.Schedules = s.scheduler.statuses()
+		}
+		return result, nil
+	case MethodList:
+		var params ListParams
+		if len(rawParams) > 0 {
+			if err := decode(&params); err != nil {
+				return nil, err
+			}
+		}
+		return w.list(params.ProjectID)
+	case MethodStatus:
+		var ref InstanceRef
+		if err := decode(&ref); err != nil {
+			return nil, err
+		}
+		tracked, err := w.find(ref)
+		if err != nil {
+			return nil, err
+		}
+		return tracked.data(), nil
+	case MethodSend:
+		var params SendParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		tracked, err := w.lookup(params.InstanceRef)
+		if err != nil {
+			return nil, err
+		}
+		resumed, err := w.sendPrompt(tracked, params.Prompt)
+		return SendResult{Resumed: resumed}, err
+	case MethodPause, MethodResume:
+		var ref InstanceRef
+		if err := decode(&ref); err != nil {
+			return nil, err
+		}
+		tracked, err := w.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		return w.setPaused(tracked, method == MethodPause)
+	case MethodKill:
+		var ref InstanceRef
+		if err := decode(&ref); err != nil {
+			return nil, err
+		}
+		tracked, err := w.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		return nil, w.kill(tracked)
+	default:
+		return nil, &Error{Code: ErrCodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", method)}
+	}
+}
@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"bufio"
+	"claude-squad/log"
+	"claude-squad/session"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs before all tests to set up the test environment
+func TestMain(m *testing.M) {
+	// Initialize the logger before any tests run
+	log.Initialize(false)
+	defer log.Close()
+
+	exitCode := m.Run()
+	os.Exit(exitCode)
+}
+
+// startTestServer serves an empty config directory on a socket in a temporary directory.
+func startTestServer(t *testing.T) string {
+	t.Helper()
+	return serveTestWatcher(t, newWatcher(t.TempDir(), nil, 0))
+}
+
+// serveTestWatcher serves a watcher on a socket in a temporary directory.
+func serveTestWatcher(t *testing.T, w *watcher) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+
+	w.mu.Lock()
+	require.NoError(t, w.rescan())
+	w.mu.Unlock()
+
+	listener, err := listen(socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	srv := &server{watcher: w, startedAt: time.Now()}
+	go srv.serve(listener)
+	return socketPath
+}
+
+func TestServerPingAndList(t *testing.T) {
+	socketPath := startTestServer(t)
+	client, err := dial(socketPath)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ping, err := client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), ping.PID)
+	assert.Equal(t, 0, ping.Instances)
+
+	instances, err := client.List("")
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+
+	_, err = client.Status("project", "missing")
+	var rpcErr *Error
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, ErrCodeNotFound, rpcErr.Code)
+}
+
+func TestServerInvalidRequests(t *testing.T) {
+	socketPath := startTestServer(t)
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+
+	tests := []struct {
+		name     string
+		request  string
+		expected int
+	}{
+		{name: "malformed json", request: `{"jsonrpc":`, expected: ErrCodeParse},
+		{name: "missing version", request: `{"id":1,"method":"ping"}`, expected: ErrCodeInvalidRequest},
+		{name: "unknown method", request: `{"jsonrpc":"2.0","id":2,"method":"frobnicate"}`, expected: ErrCodeMethodNotFound},
+		{name: "missing params", request: `{"jsonrpc":"2.0","id":3,"method":"send"}`, expected: ErrCodeInvalidParams},
+		{name: "wrong params", request: `{"jsonrpc":"2.0","id":4,"method":"status","params":[1]}`, expected: ErrCodeInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := conn.Write([]byte(tt.request + "\n"))
+			require.NoError(t, err)
+			require.True(t, scanner.Scan())
+			var resp response
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &resp))
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, tt.expected, resp.Error.Code)
+		})
+	}
+}
+
+func TestListenRefusesRunningDaemon(t *testing.T) {
+	socketPath := startTestServer(t)
+	_, err := listen(socketPath)
+	assert.Error(t, err)
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	require.NoError(t, os.WriteFile(socketPath, nil, 0600))
+
+	listener, err := listen(socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestServerResumeKeepsRunningWorktree(t *testing.T) {
+	repo := t.TempDir()
+	output, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput()
+	require.NoError(t, err, string(output))
+	w := newWatcher(t.TempDir(), nil, 0)
+	projectManager, err := w.instanceManager.GetProjectManagerForPath(repo)
+	require.NoError(t, err)
+
+	worktreePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "uncommitted.txt"), []byte("wip"), 0644))
+	require.NoError(t, projectManager.StoreInstance(session.FromInstanceDataDetached(session.InstanceData{
+		Title:    "running",
+		Path:     repo,
+		Program:  "claude",
+		Status:   session.Ready,
+		Worktree: session.GitWorktreeData{RepoPath: repo, WorktreePath: worktreePath, BranchName: "test/running"},
+	})))
+
+	client, err := dial(serveTestWatcher(t, w))
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Resume(projectManager.GetProjectID(), "running")
+	assert.ErrorContains(t, err, "is not paused")
+	assert.FileExists(t, filepath.Join(worktreePath, "uncommitted.txt"))
+}
+
+func TestWatcherCreateRejectsTitleBeingCreated(t *testing.T) {
+	repo := t.TempDir()
+	output, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	w := newWatcher(t.TempDir(), nil, 0)
+	projectManager, err := w.instanceManager.GetProjectManagerForPath(repo)
+	require.NoError(t, err)
+	// Another request is starting an instance with the title, without holding the lock.
+	w.creating[projectManager.GetProjectID()] = map[string]bool{"foo": true}
+
+	_, err = w.create(repo, session.InstanceOptions{Program: "claude"}, func(taken map[string]bool) string {
+		assert.True(t, taken["foo"])
+		return "foo"
+	})
+	assert.ErrorContains(t, err, "already being created")
+	require.True(t, w.mu.TryLock(), "the lock is released")
+	w.mu.Unlock()
+}
+
+func TestWatcherPublishResyncsSlowSubscriber(t *testing.T) {
+	w := newWatcher(t.TempDir(), nil, 0)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	events := w.subscribe()
+	defer w.unsubscribe(events)
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		w.publish(Event{Type: EventStatus, Instance: session.InstanceData{Title: fmt.Sprintf("instance-%d", i)}})
+	}
+	// The pending events are replaced by a resync, followed by the event which did not fit.
+	require.Len(t, events, 2)
+	assert.Equal(t, EventResync, (<-events).Type)
+	last := <-events
+	assert.Equal(t, EventStatus, last.Type)
+	assert.Equal(t, fmt.Sprintf("instance-%d", subscriberBuffer), last.Instance.Title)
+}
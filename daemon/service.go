@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ServiceName is the name of the systemd user unit running the daemon.
+const ServiceName = "claude-squad.service"
+
+// ServiceConfig describes how the systemd user unit runs the daemon.
+type ServiceConfig struct {
+	// Executable is the path of the claude-squad binary.
+	Executable string
+	// LogFile receives what the daemon writes to stdout and stderr, e.g. when it panics.
+	LogFile string
+	// Path is the PATH the daemon runs with, so that it finds tmux, git and the programs of the
+	// instances. The PATH of systemd's user manager usually lacks directories like ~/.local/bin.
+	Path string
+}
+
+// ServiceUnitPath returns the path of the systemd user unit of the daemon.
+func ServiceUnitPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "systemd", "user", ServiceName), nil
+}
+
+// ServiceUnit returns the content of a systemd user unit running the daemon. The daemon is
+// restarted if it fails, and reloading the unit makes it reload its config.
+func ServiceUnit(cfg ServiceConfig) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=claude-squad daemon\n")
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s daemon run\n", quoteUnitValue(cfg.Executable))
+	b.WriteString("ExecReload=/bin/kill -HUP $MAINPID\n")
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	if cfg.Path != "" {
+		fmt.Fprintf(&b, "Environment=%s\n", quoteUnitValue("PATH="+cfg.Path))
+	}
+	if cfg.LogFile != "" {
+		fmt.Fprintf(&b, "StandardOutput=append:%s\n", escapeUnitSpecifiers(cfg.LogFile))
+		fmt.Fprintf(&b, "StandardError=append:%s\n", escapeUnitSpecifiers(cfg.LogFile))
+	}
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// InstallService writes the systemd user unit of the daemon, replacing an existing one, and returns
+// its path.
+func InstallService(cfg ServiceConfig) (string, error) {
+	unitPath, err := ServiceUnitPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(unitPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create systemd user unit directory: %w", err)
+	}
+	if err := os.WriteFile(unitPath, []byte(ServiceUnit(cfg)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write systemd user unit: %w", err)
+	}
+	return unitPath, nil
+}
+
+// UninstallService removes the systemd user unit of the daemon and the link enabling it. It returns
+// the path of the unit and whether it existed.
+func UninstallService() (string, bool, error) {
+	unitPath, err := ServiceUnitPath()
+	if err != nil {
+		return "", false, err
+	}
+	// Like `systemctl --user disable`, so that no dangling link is left behind.
+	wantsLink := filepath.Join(filepath.Dir(unitPath), "default.target.wants", ServiceName)
+	if err := os.Remove(wantsLink); err != nil && !os.IsNotExist(err) {
+		return unitPath, false, fmt.Errorf("failed to disable systemd user unit: %w", err)
+	}
+	if err := os.Remove(unitPath); err != nil {
+		if os.IsNotExist(err) {
+			return unitPath, false, nil
+		}
+		return unitPath, false, fmt.Errorf("failed to remove systemd user unit: %w", err)
+	}
+	return unitPath, true, nil
+}
+
+// ServiceInstalled reports whether the systemd user unit of the daemon is installed.
+func ServiceInstalled() bool {
+	unitPath, err := ServiceUnitPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(unitPath)
+	return err == nil
+}
+
+// systemctl runs a systemctl command on the user's service manager.
+func systemctl(args ...string) error {
+	output, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// quoteUnitValue quotes a value of a unit file setting if it contains whitespace or quotes.
+func quoteUnitValue(value string) string {
+	value = escapeUnitSpecifiers(value)
+	if !strings.ContainsAny(value, " \t\"'\\") {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// escapeUnitSpecifiers escapes the % signs systemd would expand as specifiers.
+func escapeUnitSpecifiers(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceUnit(t *testing.T) {
+	unit := ServiceUnit(ServiceConfig{
+		Executable: "/home/me/my tools/cs",
+		LogFile:    "/tmp/claudesquad.log",
+		Path:       "/home/me/.local/bin:/usr/bin",
+	})
+	assert.Contains(t, unit, "ExecStart=\"/home/me/my tools/cs\" daemon run\n")
+	assert.Contains(t, unit, "ExecReload=/bin/kill -HUP $MAINPID\n")
+	assert.Contains(t, unit, "Restart=on-failure\n")
+	assert.Contains(t, unit, "Environment=PATH=/home/me/.local/bin:/usr/bin\n")
+	assert.Contains(t, unit, "StandardOutput=append:/tmp/claudesquad.log\n")
+	assert.Contains(t, unit, "StandardError=append:/tmp/claudesquad.log\n")
+	assert.Contains(t, unit, "WantedBy=default.target\n")
+
+	assert.Contains(t, ServiceUnit(ServiceConfig{Executable: "/opt/100%/cs"}), "ExecStart=/opt/100%%/cs daemon run\n")
+}
+
+func TestInstallAndUninstallService(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	unitPath := filepath.Join(configDir, "systemd", "user", ServiceName)
+
+	assert.False(t, ServiceInstalled())
+	path, err := InstallService(ServiceConfig{Executable: "/usr/bin/cs"})
+	require.NoError(t, err)
+	assert.Equal(t, unitPath, path)
+	assert.True(t, ServiceInstalled())
+	content, err := os.ReadFile(unitPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ExecStart=/usr/bin/cs daemon run")
+
+	// Enabled like systemctl does it.
+	wantsDir := filepath.Join(configDir, "systemd", "user", "default.target.wants")
+	require.NoError(t, os.MkdirAll(wantsDir, 0755))
+	require.NoError(t, os.Symlink(unitPath, filepath.Join(wantsDir, ServiceName)))
+
+	_, existed, err := UninstallService()
+	require.NoError(t, err)
+	assert.True(t, existed)
+	assert.NoFileExists(t, unitPath)
+	assert.False(t, ServiceInstalled())
+	_, err = os.Lstat(filepath.Join(wantsDir, ServiceName))
+	assert.True(t, os.IsNotExist(err))
+
+	_, existed, err = UninstallService()
+	require.NoError(t, err)
+	assert.False(t, existed)
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"errors"
+	"time"
+)
+
+// errIdentityUnknown is returned by isDaemonProcess on platforms where other processes can't be
+// inspected.
+var errIdentityUnknown = errors.New("process identity can't be checked on this platform")
+
+// Status describes the daemon as seen from another process.
+type Status struct {
+	// PID is the PID of the daemon, or the PID in a stale PID file.
+	PID     int    `json:"pid,omitempty"`
+	PIDFile string `json:"pid_file"`
+	Socket  string `json:"socket"`
+	// Running is set if a daemon process is running.
+	Running bool `json:"running"`
+	// StalePIDFile is set if the PID file names a process which has exited or is not the daemon.
+	StalePIDFile bool `json:"stale_pid_file"`
+	// Responding is set if the daemon answered on its socket. The fields below are only set then.
+	Responding bool      `json:"responding"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	Instances  int       `json:"instances"`
+	AutoYes    int       `json:"auto_yes"`
+	// Schedules are the schedules the daemon runs.
+	Schedules []ScheduleStatus `json:"schedules,omitempty"`
+}
+
+// Uptime returns how long the daemon has been running, or 0 if it did not respond.
+func (s *Status) Uptime() time.Duration {
+	if !s.Responding {
+		return 0
+	}
+	return time.Since(s.StartedAt)
+}
+
+// isDaemonArgs reports whether args, the command line of a process including the executable, are
+// those of the daemon. The hidden --daemon flag started it before the daemon subcommand existed.
+func isDaemonArgs(args []string) bool {
+	if len(args) == 2 && args[1] == "--daemon" {
+		return true
+	}
+	return len(args) >= 3 && args[1] == "daemon" && args[2] == "run"
+}
+
+// GetStatus checks the PID file, the process it names and the control socket.
+func GetStatus() (*Status, error) {
+	pidFile, err := PIDFilePath()
+	if err != nil {
+		return nil, err
+	}
+	socketPath, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{PIDFile: pidFile, Socket: socketPath}
+
+	pid, err := ReadPID()
+	if err != nil {
+		return nil, err
+	}
+	if pid != 0 {
+		status.PID = pid
+		ours, err := isDaemonProcess(pid)
+		if errors.Is(err, errIdentityUnknown) {
+			ours, err = ProcessAlive(pid), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		status.Running = ours
+		status.StalePIDFile = !ours
+	}
+
+	client, err := Dial()
+	if err != nil {
+		return status, nil
+	}
+	defer client.Close()
+	ping, err := client.Ping()
+	if err != nil {
+		return status, nil
+	}
+	// The daemon knows its PID best, e.g. if the PID file was removed.
+	status.PID = ping.PID
+	status.Running = true
+	status.StalePIDFile = pid != 0 && pid != ping.PID
+	status.Responding = true
+	status.StartedAt = ping.StartedAt
+	status.Instances = ping.Instances
+	status.AutoYes = ping.AutoYes
+	status.Schedules = ping.Schedules
+	return status, nil
+}
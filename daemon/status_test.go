@@ -0,0 +1,36 @@
+package daemon
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDaemonArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{name: "daemon subcommand", args: []string{"/usr/bin/cs", "daemon", "run"}, expected: true},
+		{name: "legacy flag", args: []string{"/usr/bin/cs", "--daemon"}, expected: true},
+		{name: "other subcommand", args: []string{"/usr/bin/cs", "daemon", "status"}, expected: false},
+		{name: "ui", args: []string{"/usr/bin/cs"}, expected: false},
+		{name: "unrelated program", args: []string{"/usr/bin/sleep", "300"}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isDaemonArgs(tt.args))
+		})
+	}
+}
+
+func TestIsDaemonProcessRejectsOtherProcesses(t *testing.T) {
+	ours, err := isDaemonProcess(os.Getpid())
+	if err == errIdentityUnknown {
+		t.Skip(err)
+	}
+	assert.NoError(t, err)
+	assert.False(t, ours)
+}
@@ -0,0 +1,580 @@
+package daemon
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// projectRescanInterval is how often the daemon looks for instances which were created, removed,
+// paused or resumed by other processes.
+const projectRescanInterval = 10 * time.Second
+
+// subscriberBuffer is the number of events buffered per subscriber. Events for subscribers which
+// don't keep up are dropped.
+const subscriberBuffer = 64
+
+// trackedInstance is an instance watched by the daemon, together with the project it is stored in.
+type trackedInstance struct {
+	instance *session.Instance
+	project  *session.ProjectInstanceManager
+	// state is shared by all copies of the trackedInstance.
+	state *instanceState
+}
+
+// instanceState is what the watcher keeps about a tracked instance besides the instance itself.
+type instanceState struct {
+	// mu serializes the work on the instance. Polling works on the instances without holding the
+	// watcher's mu, so requests and rescans changing an instance hold this lock as well. It is always
+	// taken after the watcher's mu, never the other way around.
+	mu sync.Mutex
+	// retired is set once the instance is no longer tracked, e.g. because it was killed, so that
+	// polling leaves it alone. It is guarded by mu.
+	retired bool
+	// data is the serializable data of the instance as of its last change, so that listing the
+	// instances doesn't wait for polling. It is guarded by the watcher's mu.
+	data session.InstanceData
+}
+
+// watcher keeps track of the instances of all projects and reports changes to subscribers. It owns
+// the projects whose lease it holds, running AutoYes mode, restarts and idle pausing on their
+// instances. The instances of projects owned by another process, e.g. a UI which was started while
+// the daemon was not running, are only observed.
+type watcher struct {
+	instanceManager *session.InstanceManager
+	// approvals decides which prompts AutoYes mode accepts.
+	approvals *config.ApprovalMatcher
+	// supervisor restarts instances whose session died.
+	supervisor *session.Supervisor
+	// idlePause is how long an instance has to be idle before it is paused, zero if never.
+	idlePause time.Duration
+	// autoResume resumes instances which were paused for being idle when a prompt is sent to them.
+	autoResume bool
+
+	// mu guards instances and serializes all operations on them.
+	mu        sync.Mutex
+	instances map[string]trackedInstance
+	lastScan  time.Time
+	// leases holds the lease of every known project, keyed by project ID.
+	leases map[string]*session.Lease
+	// creating holds the titles of the instances which are being started, keyed by project ID.
+	creating map[string]map[string]bool
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan Event]struct{}
+
+	// If we get an error for a session, it's likely that we'll keep getting the error.
+	everyN *log.Every
+}
+
+// newTrackedInstance starts tracking an instance nobody else works on yet.
+func newTrackedInstance(instance *session.Instance, project *session.ProjectInstanceManager) trackedInstance {
+	tracked := trackedInstance{instance: instance, project: project, state: &instanceState{}}
+	tracked.state.data = tracked.collect()
+	return tracked
+}
+
+// data returns the serializable data of the instance as of its last change. The caller must hold
+// the watcher's mu.
+func (t trackedInstance) data() session.InstanceData {
+	return t.state.data
+}
+
+// collect reads the serializable data from the instance. Polling must not be working on the
+// instance meanwhile.
+func (t trackedInstance) collect() session.InstanceData {
+	data := t.instance.ToInstanceData()
+	data.ProjectID = t.project.GetProjectID()
+	data.NeedsApproval = t.instance.NeedsApproval
+	return data
+}
+
+// paused returns whether the instance was paused as of its last change. The caller must hold the
+// watcher's mu.
+func (t trackedInstance) paused() bool {
+	return t.state.data.Status == session.Paused
+}
+
+// retire keeps polling from working on an instance which is no longer tracked.
+func (t trackedInstance) retire() {
+	t.state.mu.Lock()
+	t.state.retired = true
+	t.state.mu.Unlock()
+}
+
+func newWatcher(configDir string, approvals *config.ApprovalMatcher, idlePause time.Duration) *watcher {
+	instanceManager := session.NewInstanceManager(configDir)
+	return &watcher{
+		instanceManager: instanceManager,
+		approvals:       approvals,
+		supervisor:      session.NewSupervisor(instanceManager.RestartPolicy, true),
+		idlePause:       idlePause,
+		instances:       make(map[string]trackedInstance),
+		leases:          make(map[string]*session.Lease),
+		creating:        make(map[string]map[string]bool),
+		subscribers:     make(map[chan Event]struct{}),
+		everyN:          log.NewEvery(60 * time.Second),
+	}
+}
+
+func instanceKey(projectID, title string) string {
+	return projectID + "/" + title
+}
+
+// rescan reloads the instances of all projects from storage and takes over the projects nobody
+// owns. Instances which are already tracked are kept, so that the output they last showed is
+// remembered, unless they were paused or resumed by another process. Subscribers are told about
+// every change. The caller must hold mu.
+func (w *watcher) rescan() error {
+	projects, err := w.instanceManager.GetAllProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	instances := make(map[string]trackedInstance)
+	known := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		projectManager := w.instanceManager.GetProjectManager(project.ID, project.RepoPath)
+		known[project.ID] = true
+		w.acquireLease(projectManager)
+		projectInstances, err := projectManager.GetAllInstancesDetached()
+		if err != nil {
+			log.WarningLog.Printf("failed to load instances of project %s: %v", project.ID, err)
+			// Keep what we know about the project rather than reporting its instances as removed.
+			for key, tracked := range w.instances {
+				if tracked.project.GetProjectID() == project.ID {
+					instances[key] = tracked
+				}
+			}
+			continue
+		}
+		for _, instance := range projectInstances {
+			key := instanceKey(project.ID, instance.Title)
+			existing, ok := w.instances[key]
+			if ok && existing.paused() == instance.Paused() {
+				instances[key] = existing
+				existing.state.mu.Lock()
+				existing.instance.AutoYes = instance.AutoYes
+				if !w.owns(project.ID) && (existing.instance.Status == session.Error || instance.Status == session.Error) &&
+					(existing.instance.Status != instance.Status || existing.instance.ErrorReason != instance.ErrorReason) {
+					// The owner stores what it found out about the session.
+					existing.instance.SetStatus(instance.Status)
+					existing.instance.ErrorReason = instance.ErrorReason
+				}
+				data := existing.collect()
+				existing.state.mu.Unlock()
+				w.update(existing, data)
+				continue
+			}
+			if ok {
+				existing.retire()
+			}
+			tracked := newTrackedInstance(instance, projectManager)
+			instances[key] = tracked
+			w.publish(Event{Type: EventStatus, Instance: tracked.data()})
+		}
+	}
+	for key, tracked := range w.instances {
+		if _, ok := instances[key]; !ok {
+			tracked.retire()
+			w.publish(Event{Type: EventRemoved, Instance: tracked.data()})
+		}
+	}
+	for projectID, lease := range w.leases {
+		if !known[projectID] {
+			if err := lease.Release(); err != nil {
+				log.WarningLog.Printf("failed to release the lease of project %s: %v", projectID, err)
+			}
+			delete(w.leases, projectID)
+		}
+	}
+	w.instances = instances
+	w.lastScan = time.Now()
+	return nil
+}
+
+// acquireLease takes over the project if nobody owns it. The caller must hold mu.
+func (w *watcher) acquireLease(projectManager *session.ProjectInstanceManager) {
+	projectID := projectManager.GetProjectID()
+	lease, ok := w.leases[projectID]
+	if !ok {
+		lease = projectManager.NewLease("daemon")
+		w.leases[projectID] = lease
+	}
+	if lease.Held() {
+		return
+	}
+	if held, err := lease.TryAcquire(); err != nil {
+		log.WarningLog.Printf("failed to acquire the lease of project %s: %v", projectID, err)
+	} else if held {
+		log.InfoLog.Printf("owning the instances of project %s", projectID)
+	}
+}
+
+// owns returns whether the daemon holds the lease of the project. The caller must hold mu.
+func (w *watcher) owns(projectID string) bool {
+	lease, ok := w.leases[projectID]
+	return ok && lease.Held()
+}
+
+// releaseLeases hands all projects back, so that other processes can take them over.
+func (w *watcher) releaseLeases() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for projectID, lease := range w.leases {
+		if err := lease.Release(); err != nil {
+			log.WarningLog.Printf("failed to release the lease of project %s: %v", projectID, err)
+		}
+	}
+}
+
+// pollTask is an instance poll works on, together with whether the daemon owns its project.
+type pollTask struct {
+	tracked trackedInstance
+	owner   bool
+}
+
+// pollResult tells which changes poll made to an instance have to be stored.
+type pollResult struct {
+	tracked trackedInstance
+	// restarted is set if the supervisor restarted the session or gave up on it.
+	restarted bool
+	// paused is set if the instance was paused for being idle.
+	paused bool
+}
+
+// poll updates the status of all running instances. In the projects the daemon owns, it also
+// restarts instances whose session died as their project's restart policy allows, taps enter on
+// prompts of instances with AutoYes enabled which the approval policy allows and pauses instances
+// which have been idle for idlePause. The instances are rescanned every projectRescanInterval.
+//
+// Restarting sessions, committing the changes of idle instances and capturing panes takes a while,
+// so mu is only held to pick the instances and to store and publish what changed. Requests are
+// served meanwhile, they only wait for the instance poll is working on.
+func (w *watcher) poll() {
+	w.mu.Lock()
+	if time.Since(w.lastScan) >= projectRescanInterval {
+		if err := w.rescan(); err != nil {
+			log.WarningLog.Printf("failed to reload instances: %v", err)
+		}
+	}
+	tasks := make([]pollTask, 0, len(w.instances))
+	for _, tracked := range w.instances {
+		tasks = append(tasks, pollTask{tracked: tracked, owner: w.owns(tracked.project.GetProjectID())})
+	}
+	approvals, idlePause := w.approvals, w.idlePause
+	w.mu.Unlock()
+
+	results := make([]pollResult, 0, len(tasks))
+	for _, task := range tasks {
+		results = append(results, w.pollInstance(task, approvals, idlePause))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, result := range results {
+		tracked := result.tracked
+		if current, ok := w.instances[instanceKey(tracked.project.GetProjectID(), tracked.instance.Title)]; !ok || current.state != tracked.state {
+			// Killed or replaced meanwhile.
+			continue
+		}
+		if result.restarted {
+			if err := tracked.project.SaveStatus(tracked.instance); err != nil {
+				log.ErrorLog.Printf("failed to save the status of %s: %v", tracked.instance.Title, err)
+			}
+		}
+		if result.paused {
+			log.InfoLog.Printf("paused instance %s, it was idle for %s", tracked.instance.Title, idlePause)
+			if err := tracked.project.UpdateInstance(tracked.instance); err != nil {
+				log.ErrorLog.Printf("failed to save instance %s: %v", tracked.instance.Title, err)
+			}
+		}
+		// Requests change the instances only while holding mu, so the instance can be read.
+		w.update(tracked, tracked.collect())
+	}
+}
+
+// pollInstance does the work of poll on one instance. The caller must not hold mu.
+func (w *watcher) pollInstance(task pollTask, approvals *config.ApprovalMatcher, idlePause time.Duration) pollResult {
+	tracked := task.tracked
+	result := pollResult{tracked: tracked}
+	tracked.state.mu.Lock()
+	defer tracked.state.mu.Unlock()
+
+	instance := tracked.instance
+	// We only store started instances, but check anyway.
+	if tracked.state.retired || !instance.Started() || instance.Paused() {
+		return result
+	}
+	if task.owner && w.supervisor.Check(instance) {
+		result.restarted = true
+	}
+	if instance.Status == session.Error || (!task.owner && !instance.TmuxAlive()) {
+		// Observers learn about dead sessions from the owner when rescanning.
+		return result
+	}
+	if !task.owner {
+		instance.UpdateStatus(approvals)
+		return result
+	}
+	if hasPrompt := instance.RefreshStatus(approvals); hasPrompt && instance.AutoYes && !instance.NeedsApproval {
+		if err := instance.UpdateDiffStats(); err != nil {
+			if w.everyN.ShouldLog() {
+				log.WarningLog.Printf("could not update diff stats for %s: %v", instance.Title, err)
+			}
+		}
+	}
+	// The instance is stored once mu is held again.
+	if paused, err := instance.PauseIfIdle(idlePause); err != nil {
+		log.WarningLog.Printf("failed to pause idle instance %s: %v", instance.Title, err)
+	} else {
+		result.paused = paused
+	}
+	return result
+}
+
+// update sets the data of an instance after it changed and tells subscribers if its status changed.
+// The caller must hold mu.
+func (w *watcher) update(tracked trackedInstance, data session.InstanceData) {
+	previous := tracked.state.data
+	tracked.state.data = data
+	if data.Status != previous.Status || data.ErrorReason != previous.ErrorReason ||
+		data.NeedsApproval != previous.NeedsApproval || data.AutoYes != previous.AutoYes {
+		w.publish(Event{Type: EventStatus, Instance: data})
+	}
+}
+
+// lookup rescans the instances and returns the given one. The caller must hold mu.
+func (w *watcher) lookup(ref InstanceRef) (trackedInstance, error) {
+	if err := w.rescan(); err != nil {
+		return trackedInstance{}, err
+	}
+	tracked, ok := w.instances[instanceKey(ref.ProjectID, ref.Title)]
+	if !ok {
+		return trackedInstance{}, &Error{Code: ErrCodeNotFound, Message: fmt.Sprintf("instance not found: %s", ref.Title)}
+	}
+	return tracked, nil
+}
+
+// find returns the given instance, rescanning the instances only if it is not tracked yet. The
+// caller must hold mu.
+func (w *watcher) find(ref InstanceRef) (trackedInstance, error) {
+	if tracked, ok := w.instances[instanceKey(ref.ProjectID, ref.Title)]; ok {
+		return tracked, nil
+	}
+	return w.lookup(ref)
+}
+
+// track adds or replaces an instance after an operation changed it and tells subscribers. The
+// caller must hold mu and keep polling from working on the instance, e.g. by holding its lock.
+func (w *watcher) track(instance *session.Instance, project *session.ProjectInstanceManager) trackedInstance {
+	key := instanceKey(project.GetProjectID(), instance.Title)
+	existing, ok := w.instances[key]
+	if ok && existing.instance == instance {
+		existing.state.data = existing.collect()
+		w.publish(Event{Type: EventStatus, Instance: existing.data()})
+		return existing
+	}
+	if ok {
+		existing.retire()
+	}
+	tracked := newTrackedInstance(instance, project)
+	w.instances[key] = tracked
+	w.publish(Event{Type: EventStatus, Instance: tracked.data()})
+	return tracked
+}
+
+// create creates, starts and tracks an instance in the project of repoPath. Starting the program takes
+// a while, so mu is only held to resolve the project and the title and to store and track the
+// instance; polling and other requests go on meanwhile. title returns the title of the instance
+// given the titles which are taken. The caller must not hold mu.
+func (w *watcher) create(repoPath string, opts session.InstanceOptions, title func(taken map[string]bool) string) (session.InstanceData, error) {
+	w.mu.Lock()
+	projectManager, err := w.instanceManager.GetProjectManagerForPath(repoPath)
+	if err != nil {
+		w.mu.Unlock()
+		return session.InstanceData{}, err
+	}
+	existing, err := projectManager.GetAllInstancesData()
+	if err != nil {
+		w.mu.Unlock()
+		return session.InstanceData{}, fmt.Errorf("failed to load instances: %w", err)
+	}
+	projectID := projectManager.GetProjectID()
+	creating := w.creating[projectID]
+	taken := make(map[string]bool, len(existing)+len(creating))
+	for _, data := range existing {
+		taken[data.Title] = true
+	}
+	for pending := range creating {
+		taken[pending] = true
+	}
+	opts.Title = title(taken)
+	opts.Path = projectManager.GetRepoPath()
+	if creating[opts.Title] {
+		w.mu.Unlock()
+		return session.InstanceData{}, fmt.Errorf("instance with title '%s' is already being created", opts.Title)
+	}
+	if creating == nil {
+		creating = make(map[string]bool)
+		w.creating[projectID] = creating
+	}
+	creating[opts.Title] = true
+	w.mu.Unlock()
+
+	instance, err := projectManager.StartInstance(opts)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(creating, opts.Title)
+	if len(creating) == 0 {
+		delete(w.creating, projectID)
+	}
+	if err != nil {
+		return session.InstanceData{}, err
+	}
+	// The instance is stored under mu, since polling stores the instances of the project as well.
+	if err := projectManager.StoreInstance(instance); err != nil {
+		return session.InstanceData{}, err
+	}
+	if err := instance.ReleasePTY(); err != nil {
+		log.WarningLog.Printf("failed to release PTY of %s: %v", instance.Title, err)
+	}
+	return w.track(instance, projectManager).data(), nil
+}
+
+// sendPrompt sends a prompt to an instance, resuming it first if it was paused for being idle and
+// autoResume is set. It returns whether the instance was resumed. The caller must hold mu.
+func (w *watcher) sendPrompt(tracked trackedInstance, prompt string) (bool, error) {
+	tracked.state.mu.Lock()
+	defer tracked.state.mu.Unlock()
+	resumed, err := tracked.project.SendPrompt(tracked.instance, prompt, w.autoResume)
+	if resumed {
+		log.InfoLog.Printf("resumed instance %s to send it a prompt, it was paused for being idle", tracked.instance.Title)
+		// Resuming attaches a PTY, which the daemon has no use for.
+		if err := tracked.instance.ReleasePTY(); err != nil {
+			log.WarningLog.Printf("failed to release PTY of %s: %v", tracked.instance.Title, err)
+		}
+		w.track(tracked.instance, tracked.project)
+	}
+	return resumed, err
+}
+
+// setPaused pauses or resumes an instance and returns its data. The caller must hold mu.
+func (w *watcher) setPaused(tracked trackedInstance, pause bool) (session.InstanceData, error) {
+	tracked.state.mu.Lock()
+	defer tracked.state.mu.Unlock()
+	var err error
+	if pause {
+		err = tracked.project.PauseInstance(tracked.instance)
+	} else {
+		err = tracked.project.ResumeInstance(tracked.instance)
+	}
+	if err != nil {
+		return session.InstanceData{}, err
+	}
+	// Resuming attaches a PTY, which the daemon has no use for.
+	if err := tracked.instance.ReleasePTY(); err != nil {
+		log.WarningLog.Printf("failed to release PTY of %s: %v", tracked.instance.Title, err)
+	}
+	return w.track(tracked.instance, tracked.project).data(), nil
+}
+
+// kill kills an instance, stops tracking it and tells subscribers. The caller must hold mu.
+func (w *watcher) kill(tracked trackedInstance) error {
+	tracked.state.mu.Lock()
+	err := tracked.project.KillInstance(tracked.instance)
+	if err == nil {
+		tracked.state.retired = true
+	}
+	tracked.state.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	delete(w.instances, instanceKey(tracked.project.GetProjectID(), tracked.instance.Title))
+	w.publish(Event{Type: EventRemoved, Instance: tracked.data()})
+	return nil
+}
+
+// list returns the instances of a project, or of all projects if projectID is empty, oldest first.
+// The caller must hold mu.
+func (w *watcher) list(projectID string) ([]session.InstanceData, error) {
+	instances := make([]session.InstanceData, 0, len(w.instances))
+	for _, tracked := range w.instances {
+		if projectID == "" || tracked.project.GetProjectID() == projectID {
+			instances = append(instances, tracked.data())
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].CreatedAt.Before(instances[j].CreatedAt)
+	})
+	return instances, nil
+}
+
+// save persists the diff stats of the tracked instances to the projects the daemon owns. It is only
+// called once polling stopped.
+func (w *watcher) save() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	projects := make(map[string]*session.ProjectInstanceManager)
+	byProject := make(map[string][]*session.Instance)
+	for _, tracked := range w.instances {
+		projectID := tracked.project.GetProjectID()
+		if tracked.paused() || !w.owns(projectID) {
+			continue
+		}
+		projects[projectID] = tracked.project
+		byProject[projectID] = append(byProject[projectID], tracked.instance)
+	}
+	for projectID, projectInstances := range byProject {
+		if err := projects[projectID].SaveDiffStats(projectInstances); err != nil {
+			log.ErrorLog.Printf("failed to save instances of project %s: %v", projectID, err)
+		}
+	}
+}
+
+// subscribe returns a channel receiving all future events. The caller must hold mu.
+func (w *watcher) subscribe() chan Event {
+	ch := make(chan Event, subscriberBuffer)
+	w.subscribersMu.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.subscribersMu.Unlock()
+	return ch
+}
+
+func (w *watcher) unsubscribe(ch chan Event) {
+	w.subscribersMu.Lock()
+	delete(w.subscribers, ch)
+	w.subscribersMu.Unlock()
+}
+
+// publish sends an event to all subscribers. A subscriber whose buffer is full gets all instances
+// in place of the events it has yet to receive, followed by the event. The caller must hold mu.
+func (w *watcher) publish(event Event) {
+	w.subscribersMu.Lock()
+	defer w.subscribersMu.Unlock()
+	for ch := range w.subscribers {
+		select {
+		case ch <- event:
+			continue
+		default:
+		}
+		log.WarningLog.Printf("subscriber fell behind, sending it all instances again")
+		for drained := false; !drained; {
+			select {
+			case <-ch:
+			default:
+				drained = true
+			}
+		}
+		// Only publish sends to the channel, so there is room for both.
+		instances, _ := w.list("")
+		ch <- Event{Type: EventResync, Instances: instances}
+		ch <- event
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"claude-squad/daemon"
+	"claude-squad/log"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// daemonLogPrefix starts every line the daemon writes to the log file.
+const daemonLogPrefix = "[DAEMON]"
+
+var (
+	daemonStatusJSONFlag bool
+	daemonLogsFollowFlag bool
+	daemonLogsLinesFlag  int
+
+	daemonCmd = &cobra.Command{
+		Use:   "daemon",
+		Short: "Start, stop and inspect the background daemon",
+		Long: "Start, stop and inspect the background daemon. The daemon watches the instances of all " +
+			"projects, accepts the prompts of instances with auto-yes enabled and serves the control " +
+			"socket other frontends talk to. The UI starts it if it is not running.",
+	}
+
+	daemonStartCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Start the daemon unless it is running",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if status, err := daemon.GetStatus(); err == nil && status.Responding {
+				fmt.Printf("daemon is already running (PID %d)\n", status.PID)
+				return nil
+			}
+			return startDaemon(daemon.EnsureRunning)
+		},
+	}
+
+	daemonStopCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the daemon",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			status, err := daemon.GetStatus()
+			if err != nil {
+				return err
+			}
+			if err := daemon.StopDaemon(); err != nil {
+				return err
+			}
+			if !status.Running {
+				fmt.Println("daemon is not running")
+				return nil
+			}
+			fmt.Printf("daemon stopped (PID %d)\n", status.PID)
+			return nil
+		},
+	}
+
+	daemonRestartCmd = &cobra.Command{
+		Use:   "restart",
+		Short: "Stop the daemon if it is running and start it again",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			return startDaemon(daemon.RestartDaemon)
+		},
+	}
+
+	daemonReloadCmd = &cobra.Command{
+		Use:   "reload",
+		Short: "Make the running daemon read the config again",
+		Long: "Make the running daemon read the config file again and apply its poll interval, approval " +
+			"policy, idle pausing and schedules, without losing what it knows about the instances. " +
+			"Sending SIGHUP to the daemon does the same. An invalid config is rejected.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if !daemon.Running() {
+				return fmt.Errorf("daemon is not running")
+			}
+			client, err := daemon.Dial()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			if err := client.Reload(); err != nil {
+				return fmt.Errorf("failed to reload config: %w", err)
+			}
+			fmt.Println("daemon reloaded the config")
+			return nil
+		},
+	}
+
+	daemonInstallCmd = &cobra.Command{
+		Use:   "install",
+		Short: "Write a systemd user unit which runs the daemon",
+		Long: "Write a systemd user unit running the daemon with this binary, so that it survives " +
+			"logging out and rebooting and is restarted if it fails, and print the steps to enable it. " +
+			"The unit runs the daemon with the current PATH.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if runtime.GOOS != "linux" {
+				return fmt.Errorf("daemon install needs systemd, which is only available on Linux")
+			}
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to get executable path: %w", err)
+			}
+			unitPath, err := daemon.InstallService(daemon.ServiceConfig{
+				Executable: executable,
+				LogFile:    log.FilePath(),
+				Path:       os.Getenv("PATH"),
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("wrote %s\n\n", unitPath)
+			fmt.Println("Enable and start it with:")
+			if daemon.Running() {
+				fmt.Printf("  %s daemon stop\n", executable)
+			}
+			fmt.Println("  systemctl --user daemon-reload")
+			fmt.Printf("  systemctl --user enable --now %s\n\n", daemon.ServiceName)
+			fmt.Println("To keep it running while you are logged out:")
+			fmt.Println("  loginctl enable-linger $USER")
+			return nil
+		},
+	}
+
+	daemonUninstallCmd = &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the systemd user unit of the daemon",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			unitPath, existed, err := daemon.UninstallService()
+			if err != nil {
+				return err
+			}
+			if !existed {
+				fmt.Printf("%s is not installed\n", unitPath)
+				return nil
+			}
+			fmt.Printf("removed %s\n\n", unitPath)
+			fmt.Println("Stop the daemon it runs and make systemd forget the unit with:")
+			fmt.Printf("  systemctl --user stop %s\n", daemon.ServiceName)
+			fmt.Println("  systemctl --user daemon-reload")
+			return nil
+		},
+	}
+
+	daemonStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the daemon is running, its uptime and the instances it watches",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			status, err := daemon.GetStatus()
+			if err != nil {
+				return err
+			}
+			if daemonStatusJSONFlag {
+				out, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal status: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			printDaemonStatus(status)
+			return nil
+		},
+	}
+
+	daemonLogsCmd = &cobra.Command{
+		Use:   "logs",
+		Short: "Print the log of the daemon",
+		Long: fmt.Sprintf("Print the lines the daemon wrote to the log file %s. With --follow, keep "+
+			"printing new lines until the command is interrupted.", log.FilePath()),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if daemonLogsLinesFlag < 0 {
+				return fmt.Errorf("--lines cannot be negative")
+			}
+			return printDaemonLogs(log.FilePath(), daemonLogsLinesFlag, daemonLogsFollowFlag)
+		},
+	}
+
+	daemonRunCmd = &cobra.Command{
+		Use:    "run",
+		Short:  "Run the daemon in the foreground",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(true)
+			defer log.Close()
+
+			cfg := config.LoadConfig()
+			if err := daemon.RunDaemon(cfg); err != nil {
+				log.ErrorLog.Printf("daemon failed: %v", err)
+				return err
+			}
+			return nil
+		},
+	}
+)
+
+// startDaemon starts the daemon with start, e.g. daemon.EnsureRunning, and reports its PID.
+func startDaemon(start func() error) error {
+	if err := start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	status, err := daemon.GetStatus()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("daemon started (PID %d)\n", status.PID)
+	return nil
+}
+
+func printDaemonStatus(status *daemon.Status) {
+	switch {
+	case status.Responding:
+		fmt.Printf("daemon:    running (PID %d)\n", status.PID)
+		fmt.Printf("uptime:    %s\n", status.Uptime().Round(time.Second))
+		fmt.Printf("instances: %d watched, %d with auto-yes\n", status.Instances, status.AutoYes)
+		for i, schedule := range status.Schedules {
+			label := ""
+			if i == 0 {
+				label = "schedules:"
+			}
+			fmt.Printf("%-10s %s\n", label, formatScheduleStatus(schedule))
+		}
+	case status.Running:
+		fmt.Printf("daemon:    running (PID %d), but not responding on its socket\n", status.PID)
+	default:
+		fmt.Println("daemon:    not running")
+	}
+	fmt.Printf("socket:    %s\n", status.Socket)
+	if status.StalePIDFile {
+		fmt.Printf("pid file:  %s (stale, process %d is not the daemon)\n", status.PIDFile, status.PID)
+	} else {
+		fmt.Printf("pid file:  %s\n", status.PIDFile)
+	}
+}
+
+// formatScheduleStatus describes when a schedule runs next and how its last run went.
+func formatScheduleStatus(schedule daemon.ScheduleStatus) string {
+	const layout = "Mon Jan 2 15:04"
+	next := "never"
+	if !schedule.Next.IsZero() {
+		next = schedule.Next.Format(layout)
+	}
+	text := fmt.Sprintf("%s (%s), next %s", schedule.Name, schedule.Action, next)
+	switch {
+	case schedule.Running:
+		text += ", running"
+	case schedule.LastRun.IsZero():
+	case schedule.LastError != "":
+		text += fmt.Sprintf(", last run %s failed: %s", schedule.LastRun.Format(layout), schedule.LastError)
+	default:
+		text += fmt.Sprintf(", last run %s on %s", schedule.LastRun.Format(layout), schedule.LastInstance)
+	}
+	return text
+}
+
+// printDaemonLogs prints the last n lines of the daemon in the log file, or all of them if n is 0.
+// With follow, lines appended later are printed as well.
+func printDaemonLogs(path string, n int, follow bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && !follow {
+			return nil
+		}
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, daemonLogPrefix) {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	for _, line := range lastLines(lines, n) {
+		fmt.Println(line)
+	}
+	if !follow {
+		return nil
+	}
+
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(logsPollInterval)
+	defer ticker.Stop()
+	// partial holds a line which was not completely written yet.
+	var partial string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+		if info.Size() < offset {
+			// The log file was truncated.
+			offset, partial = 0, ""
+		}
+		if info.Size() == offset {
+			continue
+		}
+		buf := make([]byte, info.Size()-offset)
+		read, err := f.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read log file: %w", err)
+		}
+		offset += int64(read)
+		chunk := partial + string(buf[:read])
+		newLines := strings.Split(chunk, "\n")
+		partial = newLines[len(newLines)-1]
+		for _, line := range newLines[:len(newLines)-1] {
+			if strings.HasPrefix(line, daemonLogPrefix) {
+				fmt.Println(line)
+			}
+		}
+	}
+}
+
+func init() {
+	daemonStatusCmd.Flags().BoolVar(&daemonStatusJSONFlag, "json", false, "Print the status as JSON")
+	daemonLogsCmd.Flags().BoolVarP(&daemonLogsFollowFlag, "follow", "f", false, "Keep printing new lines as they are written")
+	daemonLogsCmd.Flags().IntVarP(&daemonLogsLinesFlag, "lines", "n", 50, "Only print the last N lines (0 for all)")
+
+	daemonCmd.AddCommand(daemonStartCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonRestartCmd)
+	daemonCmd.AddCommand(daemonReloadCmd)
+	daemonCmd.AddCommand(daemonInstallCmd)
+	daemonCmd.AddCommand(daemonUninstallCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonLogsCmd)
+	daemonCmd.AddCommand(daemonRunCmd)
+
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffStatFlag bool
+	diffJSONFlag bool
+
+	diffCmd = &cobra.Command{
+		Use:   "diff <title>",
+		Short: "Show the changes of an instance against its base commit",
+		Long: "Show the changes of an instance against its base commit as a raw patch, a per-file " +
+			"summary with --stat or per-file counts as JSON with --json.\n\n" +
+			"Paused instances have no worktree, so the diff stored when they were paused is shown.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if diffStatFlag && diffJSONFlag {
+				return fmt.Errorf("--stat and --json cannot be combined")
+			}
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			instance, err := projectManager.GetInstanceDetached(args[0])
+			if err != nil {
+				return err
+			}
+			// Paused instances keep the stats they were stored with.
+			if err := instance.UpdateDiffStats(); err != nil {
+				return err
+			}
+			stats := instance.GetDiffStats()
+			if stats == nil {
+				stats = &git.DiffStats{}
+			}
+
+			switch {
+			case diffJSONFlag:
+				files := stats.Files()
+				if files == nil {
+					files = []git.FileDiffStats{}
+				}
+				out, err := json.MarshalIndent(struct {
+					Title   string              `json:"title"`
+					Branch  string              `json:"branch"`
+					Added   int                 `json:"added"`
+					Removed int                 `json:"removed"`
+					Files   []git.FileDiffStats `json:"files"`
+				}{instance.Title, instance.Branch, stats.Added, stats.Removed, files}, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal diff: %w", err)
+				}
+				fmt.Println(string(out))
+			case diffStatFlag:
+				files := stats.Files()
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				for _, f := range files {
+					fmt.Fprintf(w, "%s\t+%d\t-%d\n", f.Path, f.Added, f.Removed)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+				fmt.Printf("%d files changed, %d insertions(+), %d deletions(-)\n",
+					len(files), stats.Added, stats.Removed)
+			default:
+				fmt.Print(stats.Content)
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffStatFlag, "stat", false, "Print a per-file summary instead of the patch")
+	diffCmd.Flags().BoolVar(&diffJSONFlag, "json", false, "Print per-file added and removed line counts as JSON")
+
+	rootCmd.AddCommand(diffCmd)
+}
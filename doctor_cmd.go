@@ -0,0 +1,307 @@
+package main
+
+import (
+	"claude-squad/config"
+	"claude-squad/daemon"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type doctorLevel int
+
+const (
+	doctorOK doctorLevel = iota
+	doctorWarn
+	doctorFail
+)
+
+func (l doctorLevel) String() string {
+	switch l {
+	case doctorWarn:
+		return "warn"
+	case doctorFail:
+		return "FAIL"
+	default:
+		return "ok"
+	}
+}
+
+// doctorFinding is the result of a single doctor check.
+type doctorFinding struct {
+	name   string
+	level  doctorLevel
+	detail string
+	// fix tells the user how to resolve the problem.
+	fix string
+	// autoFix resolves the problem with --fix. It's nil if the fix is not safe to apply automatically.
+	autoFix func() error
+}
+
+var (
+	doctorFixFlag bool
+
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment and the state of all instances for problems",
+		Long: "Check the tools claude-squad depends on, the config file, the daemon and the state of every " +
+			"stored instance, and print how to fix each problem found.\n\n" +
+			"With --fix, the safe fixes are applied: removing a stale daemon PID file and correcting " +
+			"the stored status of instances whose worktree and tmux session disagree with it.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			findings := []doctorFinding{
+				checkTool("tmux", "-V"),
+				checkTool("git", "--version"),
+				checkGH(),
+				checkClaudeCommand(),
+				checkConfig(),
+				checkDaemonPID(),
+			}
+			findings = append(findings, checkInstances()...)
+
+			problems := 0
+			for _, finding := range findings {
+				fmt.Printf("[%s] %s: %s\n", finding.level, finding.name, finding.detail)
+				if finding.level == doctorOK {
+					continue
+				}
+				if doctorFixFlag && finding.autoFix != nil {
+					if err := finding.autoFix(); err != nil {
+						fmt.Printf("       fix failed: %v\n", err)
+					} else {
+						fmt.Printf("       fixed\n")
+						continue
+					}
+				} else if finding.fix != "" {
+					fmt.Printf("       fix: %s\n", finding.fix)
+				}
+				if finding.level == doctorFail {
+					problems++
+				}
+			}
+
+			if problems > 0 {
+				return fmt.Errorf("%d problems found", problems)
+			}
+			return nil
+		},
+	}
+)
+
+// checkTool checks that a required tool is installed and reports its version.
+func checkTool(name string, versionArg string) doctorFinding {
+	finding := doctorFinding{name: name}
+	output, err := exec.Command(name, versionArg).CombinedOutput()
+	if err != nil {
+		finding.level = doctorFail
+		finding.detail = fmt.Sprintf("not found or not working: %v", err)
+		finding.fix = fmt.Sprintf("install %s and make sure it is in your PATH", name)
+		return finding
+	}
+	finding.detail = strings.TrimSpace(string(output))
+	return finding
+}
+
+func checkGH() doctorFinding {
+	finding := doctorFinding{name: "gh", detail: "installed and authenticated"}
+	if err := git.CheckGHCLI(); err != nil {
+		finding.level = doctorWarn
+		finding.detail = err.Error()
+		finding.fix = "only needed to push branches from the UI, install gh and run 'gh auth login'"
+	}
+	return finding
+}
+
+func checkClaudeCommand() doctorFinding {
+	finding := doctorFinding{name: "claude"}
+	path, err := config.GetClaudeCommand()
+	if err != nil {
+		finding.level = doctorWarn
+		finding.detail = err.Error()
+		finding.fix = "install Claude Code, or set default_program in the config to the program you use"
+		return finding
+	}
+	finding.detail = path
+	return finding
+}
+
+func checkConfig() doctorFinding {
+	finding := doctorFinding{name: "config"}
+	cfg, err := config.ReadConfig()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		finding.detail = "no config file yet, using defaults"
+	case err != nil:
+		// LoadConfig silently replaces a broken config with the defaults, so this is easy to miss.
+		finding.level = doctorFail
+		finding.detail = fmt.Sprintf("%v; the defaults are used instead", err)
+		finding.fix = "run 'claude-squad config edit', or delete the file to regenerate it with the defaults"
+	case cfg.Validate() != nil:
+		finding.level = doctorWarn
+		finding.detail = strings.ReplaceAll(cfg.Validate().Error(), "\n", "; ")
+		finding.fix = "run 'claude-squad config edit' or 'claude-squad config set <key> <value>'"
+	default:
+		finding.detail = fmt.Sprintf("default program %q, branch prefix %q", cfg.DefaultProgram, cfg.BranchPrefix)
+	}
+	return finding
+}
+
+func checkDaemonPID() doctorFinding {
+	finding := doctorFinding{name: "daemon"}
+	pidFile, err := daemon.PIDFilePath()
+	if err != nil {
+		finding.level = doctorFail
+		finding.detail = err.Error()
+		return finding
+	}
+	removePIDFile := func() error { return os.Remove(pidFile) }
+
+	if _, err := daemon.ReadPID(); err != nil {
+		finding.level = doctorFail
+		finding.detail = err.Error()
+		finding.fix = fmt.Sprintf("remove %s", pidFile)
+		finding.autoFix = removePIDFile
+		return finding
+	}
+	status, err := daemon.GetStatus()
+	switch {
+	case err != nil:
+		finding.level = doctorFail
+		finding.detail = err.Error()
+	case status.StalePIDFile && !status.Running:
+		finding.level = doctorFail
+		finding.detail = fmt.Sprintf("stale PID file, process %d is not the daemon", status.PID)
+		finding.fix = fmt.Sprintf("remove %s", pidFile)
+		finding.autoFix = removePIDFile
+	case status.Running && !status.Responding:
+		finding.level = doctorFail
+		finding.detail = fmt.Sprintf("running with PID %d, but not responding on %s", status.PID, status.Socket)
+		finding.fix = "run 'claude-squad daemon restart'"
+	case status.Running:
+		finding.detail = fmt.Sprintf("running with PID %d, watching %d instances", status.PID, status.Instances)
+	default:
+		finding.detail = "not running"
+	}
+	return finding
+}
+
+// checkInstances checks every stored instance of every project.
+func checkInstances() []doctorFinding {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return []doctorFinding{{name: "instances", level: doctorFail, detail: err.Error()}}
+	}
+	instanceManager := session.NewInstanceManager(configDir)
+	projects, err := instanceManager.GetAllProjects()
+	if err != nil {
+		return []doctorFinding{{name: "instances", level: doctorFail, detail: fmt.Sprintf("failed to load projects: %v", err)}}
+	}
+
+	var findings []doctorFinding
+	total := 0
+	for _, project := range projects {
+		projectManager := instanceManager.GetProjectManager(project.ID, project.RepoPath)
+		instances, err := projectManager.GetAllInstancesDetached()
+		if err != nil {
+			findings = append(findings, doctorFinding{
+				name:   "project " + project.Name,
+				level:  doctorFail,
+				detail: fmt.Sprintf("failed to load instances: %v", err),
+				fix: fmt.Sprintf("fix or remove %s",
+					filepath.Join(configDir, session.ProjectsDirName, project.ID, session.ProjectStateFileName)),
+			})
+			continue
+		}
+		for _, instance := range instances {
+			total++
+			if finding, ok := checkInstance(projectManager, project, instance); ok {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, doctorFinding{
+			name:   "instances",
+			detail: fmt.Sprintf("%d instances in %d projects are consistent", total, len(projects)),
+		})
+	}
+	return findings
+}
+
+// checkInstance diagnoses a single instance. It returns false if the instance has no problem.
+func checkInstance(projectManager *session.ProjectInstanceManager, project config.GlobalProjectData,
+	instance *session.Instance) (doctorFinding, bool) {
+	finding := doctorFinding{name: fmt.Sprintf("instance %s/%s", project.Name, instance.Title), level: doctorFail}
+	// The instance commands act on the project of the working directory.
+	inRepo := func(command string) string {
+		return fmt.Sprintf("run 'cd %s && claude-squad %s %s'", project.RepoPath, command, instance.Title)
+	}
+
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		finding.detail = err.Error()
+		return finding, true
+	}
+	worktreeExists := false
+	if _, err := os.Stat(worktree.GetWorktreePath()); err == nil {
+		worktreeExists = true
+	}
+	tmuxExists := instance.TmuxAlive()
+
+	// Let the instance correct its own status first.
+	stored := instance.Status
+	if err := instance.VerifyStateConsistency(); err != nil {
+		finding.detail = fmt.Sprintf("failed to verify state: %v", err)
+		return finding, true
+	}
+	if instance.Status != stored {
+		finding.detail = fmt.Sprintf("stored as %s, but worktree exists=%v and tmux session exists=%v, should be %s",
+			stored, worktreeExists, tmuxExists, instance.Status)
+		finding.fix = "rerun with --fix to store the corrected status"
+		finding.autoFix = func() error { return projectManager.UpdateInstance(instance) }
+		return finding, true
+	}
+
+	state := fmt.Sprintf("worktree exists=%v, tmux session exists=%v", worktreeExists, tmuxExists)
+	switch {
+	case instance.Status == session.Paused:
+		return finding, false
+	case instance.Status == session.Error && worktreeExists && !tmuxExists:
+		finding.detail = "in error state because its tmux session died"
+		finding.fix = inRepo("restart")
+	case instance.Status == session.Error && !worktreeExists:
+		finding.detail = "in error state and its worktree is missing; " + state
+		finding.fix = inRepo("resume")
+	case instance.Status == session.Error:
+		finding.detail = "in error state, but its worktree and tmux session exist"
+		finding.fix = inRepo("attach") + " to inspect it, or 'claude-squad kill' it"
+	case worktreeExists && !tmuxExists:
+		finding.detail = fmt.Sprintf("%s, but its tmux session died", instance.Status)
+		finding.fix = inRepo("restart")
+	case !worktreeExists:
+		finding.detail = fmt.Sprintf("%s, but its worktree is missing; %s", instance.Status, state)
+		finding.fix = inRepo("kill") + ", the branch " + worktree.GetBranchName() + " is deleted as well"
+	default:
+		return finding, false
+	}
+	return finding, true
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFixFlag, "fix", false, "Apply the fixes which are safe to apply automatically")
+
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutputFlag string
+	importTitleFlag  string
+
+	exportCmd = &cobra.Command{
+		Use:   "export <title>",
+		Short: "Package an instance into an archive which can be imported elsewhere",
+		Long: "Package an instance into a .tar.gz archive containing its metadata, its branch as a git " +
+			"bundle of the commits since its base commit, and its pane history. Uncommitted changes of a " +
+			"running instance are committed first. The instance itself is left as it is.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			output := exportOutputFlag
+			if output == "" {
+				output = args[0] + ".tar.gz"
+			}
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to create archive: %w", err)
+			}
+			manifest, err := projectManager.ExportInstance(args[0], f)
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("failed to write archive: %w", closeErr)
+			}
+			if err != nil {
+				os.Remove(output)
+				return err
+			}
+
+			fmt.Printf("Exported '%s' to %s (%d commits on %s", manifest.Instance.Title, output,
+				manifest.Commits, manifest.Instance.Branch)
+			if manifest.HasHistory {
+				fmt.Print(", with pane history")
+			}
+			fmt.Println(")")
+			return nil
+		},
+	}
+
+	importCmd = &cobra.Command{
+		Use:   "import <file>",
+		Short: "Recreate an exported instance in the current project as a paused instance",
+		Long: "Recreate an instance exported with 'export' in the current project. The instance branch " +
+			"is created from the archive and the instance is added in the paused state, resume it to " +
+			"get a worktree and a tmux session. The base commit of the instance has to be present in the " +
+			"repository. The exported pane history is available through 'logs' while the instance is paused.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open archive: %w", err)
+			}
+			defer f.Close()
+
+			instance, manifest, err := projectManager.ImportInstance(f, importTitleFlag)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported '%s' as paused instance '%s' on branch %s (%d commits), resume it to continue\n",
+				manifest.Instance.Title, instance.Title, instance.Branch, manifest.Commits)
+			return nil
+		},
+	}
+)
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutputFlag, "output", "o", "", "Path of the archive to write (default <title>.tar.gz)")
+	importCmd.Flags().StringVar(&importTitleFlag, "title", "", "Title of the imported instance (default the exported title)")
+
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcDryRunFlag   bool
+	gcBranchesFlag bool
+
+	gcCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "Remove worktrees, branches and tmux sessions no instance owns",
+		Long: "Find worktrees, branches and tmux sessions created by claude-squad which no stored instance " +
+			"of any project owns, e.g. after a crash, and remove them.\n\n" +
+			"Branches are only removed if they were checked out in an orphaned worktree. Use --branches " +
+			"to also remove every other branch starting with the configured branch prefix which no " +
+			"instance owns. Resources created within the last minute are left alone.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			branchPrefix := config.LoadConfig().BranchPrefix
+			if gcBranchesFlag && branchPrefix == "" {
+				return fmt.Errorf("--branches requires a non-empty branch_prefix in the config")
+			}
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			orphans, err := instanceManager.FindOrphans(branchPrefix, gcBranchesFlag)
+			if err != nil {
+				return fmt.Errorf("failed to find orphaned resources: %w", err)
+			}
+			if len(orphans) == 0 {
+				fmt.Println("Nothing to clean up")
+				return nil
+			}
+
+			var total int64
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tNAME\tREPO\tSIZE")
+			for _, orphan := range orphans {
+				repo, size := orphan.RepoPath, "-"
+				if repo == "" {
+					repo = "-"
+				}
+				if orphan.Kind == session.OrphanWorktree {
+					size = formatSize(orphan.Size)
+				}
+				total += orphan.Size
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", orphan.Kind, orphan.Name, repo, size)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if gcDryRunFlag {
+				fmt.Printf("\n%d orphaned resources, %s reclaimable. Run without --dry-run to remove them\n",
+					len(orphans), formatSize(total))
+				return nil
+			}
+
+			fmt.Println()
+			failed := 0
+			for _, orphan := range orphans {
+				if err := session.RemoveOrphan(orphan); err != nil {
+					failed++
+					log.ErrorLog.Printf("gc: failed to remove %s %s: %v", orphan.Kind, orphan.Name, err)
+					fmt.Printf("%s %s: failed: %v\n", orphan.Kind, orphan.Name, err)
+					continue
+				}
+				fmt.Printf("%s %s: removed\n", orphan.Kind, orphan.Name)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d orphaned resources could not be removed", failed, len(orphans))
+			}
+			fmt.Printf("Removed %d orphaned resources, reclaimed %s\n", len(orphans), formatSize(total))
+			return nil
+		},
+	}
+)
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcDryRunFlag, "dry-run", false, "Only report orphaned resources without removing them")
+	gcCmd.Flags().BoolVar(&gcBranchesFlag, "branches", false,
+		"Also remove branches with the configured prefix which no instance owns")
+
+	rootCmd.AddCommand(gcCmd)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"claude-squad/daemon"
+	"claude-squad/log"
+	"claude-squad/session"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// instanceSelector selects instances of the current project by title, by status or all of them.
+type instanceSelector struct {
+	all    bool
+	status string
+}
+
+func (s *instanceSelector) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&s.all, "all", false, "Select all instances of the current project")
+	cmd.Flags().StringVar(&s.status, "status", "",
+		"Select all instances with the given status (running, ready, paused, error, ...)")
+}
+
+// selectInstances returns the selected instances without attaching to their tmux sessions.
+func (s *instanceSelector) selectInstances(projectManager *session.ProjectInstanceManager, titles []string) ([]*session.Instance, error) {
+	if len(titles) > 0 && (s.all || s.status != "") {
+		return nil, fmt.Errorf("instance titles cannot be combined with --all or --status")
+	}
+	if len(titles) == 0 && !s.all && s.status == "" {
+		return nil, fmt.Errorf("specify instance titles, --all or --status")
+	}
+
+	if len(titles) > 0 {
+		instances := make([]*session.Instance, 0, len(titles))
+		for _, title := range titles {
+			instance, err := projectManager.GetInstanceDetached(title)
+			if err != nil {
+				return nil, err
+			}
+			instances = append(instances, instance)
+		}
+		return instances, nil
+	}
+
+	instances, err := projectManager.GetAllInstancesDetached()
+	if err != nil {
+		return nil, err
+	}
+	if s.status == "" {
+		return instances, nil
+	}
+
+	status, err := session.ParseStatus(s.status)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]*session.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Status == status {
+			selected = append(selected, instance)
+		}
+	}
+	return selected, nil
+}
+
+// lifecycleAction applies an operation to an instance and returns a short description of the outcome.
+type lifecycleAction func(controller *daemon.Controller, projectManager *session.ProjectInstanceManager,
+	instance *session.Instance) (string, error)
+
+// newLifecycleCmd creates a command which applies action to every selected instance. It reports
+// the outcome per instance and fails if any of the actions failed.
+func newLifecycleCmd(use, short string, action lifecycleAction) *cobra.Command {
+	selector := &instanceSelector{}
+	cmd := &cobra.Command{
+		Use:   use + " [title...]",
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			instances, err := selector.selectInstances(projectManager, args)
+			if err != nil {
+				return err
+			}
+			if len(instances) == 0 {
+				fmt.Println("No matching instances")
+				return nil
+			}
+			controller := daemon.NewController(projectManager)
+			defer controller.Close()
+
+			failed := 0
+			for _, instance := range instances {
+				outcome, err := action(controller, projectManager, instance)
+				if err != nil {
+					failed++
+					log.ErrorLog.Printf("failed to %s instance %s: %v", use, instance.Title, err)
+					fmt.Printf("%s: failed: %v\n", instance.Title, err)
+					continue
+				}
+				fmt.Printf("%s: %s\n", instance.Title, outcome)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d instances failed to %s", failed, len(instances), use)
+			}
+			return nil
+		},
+	}
+	selector.addFlags(cmd)
+	return cmd
+}
+
+var (
+	pauseCmd = newLifecycleCmd("pause",
+		"Commit changes, remove the worktree and keep the branch of instances",
+		func(controller *daemon.Controller, projectManager *session.ProjectInstanceManager, instance *session.Instance) (string, error) {
+			if instance.Paused() {
+				return "already paused", nil
+			}
+			_, err := controller.Pause(instance)
+			return "paused", err
+		})
+
+	resumeCmd = newLifecycleCmd("resume",
+		"Recreate the worktree and restart the tmux session of paused instances",
+		func(controller *daemon.Controller, projectManager *session.ProjectInstanceManager, instance *session.Instance) (string, error) {
+			if !instance.Paused() {
+				return "not paused", nil
+			}
+			_, err := controller.Resume(instance)
+			return "resumed", err
+		})
+
+	killCmd = newLifecycleCmd("kill",
+		"Kill instances and remove their worktrees and branches",
+		func(controller *daemon.Controller, projectManager *session.ProjectInstanceManager, instance *session.Instance) (string, error) {
+			return "killed", controller.Kill(instance)
+		})
+
+	restartCmd = newLifecycleCmd("restart",
+		"Restart the tmux session of instances whose session died, keeping the worktree",
+		func(controller *daemon.Controller, projectManager *session.ProjectInstanceManager, instance *session.Instance) (string, error) {
+			if instance.Paused() {
+				return "", fmt.Errorf("instance is paused, use resume instead")
+			}
+			if instance.TmuxAlive() {
+				return "tmux session is already running", nil
+			}
+			if err := instance.RestartTmux(); err != nil {
+				return "", err
+			}
+			return "restarted", projectManager.UpdateInstance(instance)
+		})
+)
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(killCmd)
+	rootCmd.AddCommand(restartCmd)
+}
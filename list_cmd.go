@@ -0,0 +1,128 @@
+package main
+
+import (
+	"claude-squad/config"
+	"claude-squad/daemon"
+	"claude-squad/log"
+	"claude-squad/session"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listJSONFlag        bool
+	listAllProjectsFlag bool
+
+	listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List instances of the current project without starting the UI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			var projects []config.GlobalProjectData
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			if listAllProjectsFlag {
+				projects, err = instanceManager.GetAllProjects()
+				if err != nil {
+					return fmt.Errorf("failed to load projects: %w", err)
+				}
+			} else {
+				projectManager, err := currentProjectManager()
+				if err != nil {
+					return err
+				}
+				project, err := projectManager.GetProjectData()
+				if err != nil {
+					return fmt.Errorf("failed to load project: %w", err)
+				}
+				if project == nil {
+					return fmt.Errorf("project %s is not registered", projectManager.GetProjectID())
+				}
+				projects = []config.GlobalProjectData{*project}
+			}
+
+			// Collect the stored data only, unless the daemon is running, which knows whether running
+			// instances are busy. Listing must not attach to any tmux session.
+			client, err := daemon.Dial()
+			if err != nil {
+				client = nil
+			} else {
+				defer client.Close()
+			}
+			instances := make([]session.InstanceData, 0)
+			projectNames := make(map[string]string)
+			for _, project := range projects {
+				var data []session.InstanceData
+				if client != nil {
+					data, err = client.List(project.ID)
+				} else {
+					data, err = instanceManager.GetProjectManager(project.ID, project.RepoPath).GetAllInstancesData()
+				}
+				if err != nil {
+					return fmt.Errorf("failed to load instances of project %s: %w", project.Name, err)
+				}
+				for _, d := range data {
+					d.ProjectID = project.ID
+					instances = append(instances, d)
+				}
+				projectNames[project.ID] = project.Name
+			}
+
+			if listJSONFlag {
+				out, err := json.MarshalIndent(instances, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal instances: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			if listAllProjectsFlag {
+				fmt.Fprint(w, "PROJECT\t")
+			}
+			fmt.Fprintln(w, "TITLE\tNAME\tSTATUS\tBRANCH\tPROGRAM\tDIFF\tAGE")
+			for _, d := range instances {
+				if listAllProjectsFlag {
+					fmt.Fprintf(w, "%s\t", projectNames[d.ProjectID])
+				}
+				displayName := d.DisplayName
+				if displayName == "" {
+					displayName = d.Title
+				}
+				if d.ScheduledBy != "" {
+					displayName += fmt.Sprintf(" [%s]", d.ScheduledBy)
+				}
+				status := d.Status.String()
+				if d.NeedsApproval {
+					status = "needs approval"
+				} else if d.Status == session.Error && d.ErrorReason != "" {
+					status = fmt.Sprintf("error (%s)", d.ErrorReason)
+				} else if d.Status == session.Paused && d.AutoPaused {
+					status = "paused (idle)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t+%d/-%d\t%s\n",
+					d.Title, displayName, status, d.Branch, d.Program,
+					d.DiffStats.Added, d.DiffStats.Removed, formatAge(d.CreatedAt))
+			}
+			return w.Flush()
+		},
+	}
+)
+
+func init() {
+	listCmd.Flags().BoolVar(&listJSONFlag, "json", false, "Print instances as JSON")
+	listCmd.Flags().BoolVarP(&listAllProjectsFlag, "all-projects", "a", false,
+		"List instances of every known project instead of the current one")
+
+	rootCmd.AddCommand(listCmd)
+}
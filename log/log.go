@@ -42,10 +42,16 @@ func Initialize(daemon bool) {
 	globalLogFile = f
 }
 
+// FilePath returns the path of the log file.
+func FilePath() string {
+	return logFileName
+}
+
 func Close() {
 	_ = globalLogFile.Close()
 	// TODO: maybe only print if verbose flag is set?
-	fmt.Println("wrote logs to " + logFileName)
+	// Print to stderr so that machine-readable output of subcommands on stdout stays clean.
+	fmt.Fprintln(os.Stderr, "wrote logs to "+logFileName)
 }
 
 // Every is used to log at most once every timeout duration.
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"claude-squad/log"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/cobra"
+)
+
+// logsPollInterval is how often the pane is captured in follow mode.
+const logsPollInterval = 500 * time.Millisecond
+
+var (
+	logsFollowFlag bool
+	logsLinesFlag  int
+	logsPlainFlag  bool
+
+	logsCmd = &cobra.Command{
+		Use:   "logs <title>",
+		Short: "Print the output of an instance",
+		Long: "Print the scrollback of an instance's tmux pane. With --follow, keep printing new output " +
+			"as it appears until the instance's session ends or the command is interrupted. Only lines " +
+			"above the cursor are followed, since the line being typed may still change.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if logsLinesFlag < 0 {
+				return fmt.Errorf("--lines cannot be negative")
+			}
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			instance, err := projectManager.GetInstanceDetached(args[0])
+			if err != nil {
+				return err
+			}
+			if instance.Paused() {
+				// Imported instances show the history they were exported with.
+				history, err := projectManager.ImportedHistory(instance.Title)
+				if err != nil || logsFollowFlag {
+					return fmt.Errorf("instance '%s' is paused and has no output", instance.Title)
+				}
+				lines := strings.Split(strings.TrimRight(history, "\n"), "\n")
+				printLogLines(lastLines(lines, logsLinesFlag))
+				return nil
+			}
+			if !instance.TmuxAlive() {
+				return fmt.Errorf("tmux session of instance '%s' is not running", instance.Title)
+			}
+
+			if !logsFollowFlag {
+				content, err := instance.PreviewFullHistory()
+				if err != nil {
+					return err
+				}
+				// The visible part of the pane is padded with empty lines.
+				lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+				printLogLines(lastLines(lines, logsLinesFlag))
+				return nil
+			}
+
+			follower, err := instance.FollowHistory()
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			lines, err := follower.Poll()
+			if err != nil {
+				return err
+			}
+			printLogLines(lastLines(lines, logsLinesFlag))
+
+			ticker := time.NewTicker(logsPollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+				if !instance.TmuxAlive() {
+					fmt.Fprintf(os.Stderr, "tmux session of instance '%s' ended\n", instance.Title)
+					return nil
+				}
+				lines, err := follower.Poll()
+				if err != nil {
+					return err
+				}
+				printLogLines(lines)
+			}
+		},
+	}
+)
+
+// lastLines returns the last n lines, or all of them if n is 0.
+func lastLines(lines []string, n int) []string {
+	if n == 0 || n >= len(lines) {
+		return lines
+	}
+	return lines[len(lines)-n:]
+}
+
+func printLogLines(lines []string) {
+	for _, line := range lines {
+		if logsPlainFlag {
+			line = ansi.Strip(line)
+		}
+		fmt.Println(line)
+	}
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollowFlag, "follow", "f", false, "Keep printing new output as it appears")
+	logsCmd.Flags().IntVarP(&logsLinesFlag, "lines", "n", 0, "Only print the last N lines of the existing output (0 for all)")
+	logsCmd.Flags().BoolVar(&logsPlainFlag, "plain", false, "Strip ANSI escape sequences from the output")
+
+	rootCmd.AddCommand(logsCmd)
+}
@@ -2,16 +2,14 @@ package main
 
 import (
 	"claude-squad/app"
-	cmd2 "claude-squad/cmd"
 	"claude-squad/config"
 	"claude-squad/daemon"
 	"claude-squad/log"
-	"claude-squad/session"
 	"claude-squad/session/git"
-	"claude-squad/session/tmux"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
@@ -21,22 +19,17 @@ var (
 	version     = "1.0.13"
 	programFlag string
 	autoYesFlag bool
-	daemonFlag  bool
 	rootCmd     = &cobra.Command{
 		Use:   "claude-squad",
 		Short: "Claude Squad - Manage multiple AI agents like Claude Code, Aider, Codex, and Amp.",
+		// Errors are printed by main. Most of them are runtime errors, so don't bury them under the usage.
+		SilenceUsage:  true,
+		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
-			log.Initialize(daemonFlag)
+			log.Initialize(false)
 			defer log.Close()
 
-			if daemonFlag {
-				cfg := config.LoadConfig()
-				err := daemon.RunDaemon(cfg)
-				log.ErrorLog.Printf("failed to start daemon %v", err)
-				return err
-			}
-
 			// Check if we're in a git repository
 			currentDir, err := filepath.Abs(".")
 			if err != nil {
@@ -59,59 +52,17 @@ var (
 			if autoYesFlag {
 				autoYes = true
 			}
-			if autoYes {
-				defer func() {
-					if err := daemon.LaunchDaemon(); err != nil {
-						log.ErrorLog.Printf("failed to launch daemon: %v", err)
-					}
-				}()
-			}
-			// Kill any daemon that's running.
-			if err := daemon.StopDaemon(); err != nil {
-				log.ErrorLog.Printf("failed to stop daemon: %v", err)
+			// The daemon keeps running alongside the UI and after it exits. It owns the instances of
+			// all projects, accepting the prompts of instances with AutoYes enabled, and serves other
+			// frontends like the CLI. The UI only takes its project over if the daemon doesn't run.
+			if err := daemon.EnsureRunning(); err != nil {
+				log.ErrorLog.Printf("failed to launch daemon: %v", err)
 			}
 
 			return app.Run(ctx, program, autoYes)
 		},
 	}
 
-	resetCmd = &cobra.Command{
-		Use:   "reset",
-		Short: "Reset all stored instances",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			log.Initialize(false)
-			defer log.Close()
-
-			state := config.LoadState()
-			storage, err := session.NewStorage(state)
-			if err != nil {
-				return fmt.Errorf("failed to initialize storage: %w", err)
-			}
-			if err := storage.DeleteAllInstances(); err != nil {
-				return fmt.Errorf("failed to reset storage: %w", err)
-			}
-			fmt.Println("Storage has been reset successfully")
-
-			if err := tmux.CleanupSessions(cmd2.MakeExecutor()); err != nil {
-				return fmt.Errorf("failed to cleanup tmux sessions: %w", err)
-			}
-			fmt.Println("Tmux sessions have been cleaned up")
-
-			if err := git.CleanupWorktrees(); err != nil {
-				return fmt.Errorf("failed to cleanup worktrees: %w", err)
-			}
-			fmt.Println("Worktrees have been cleaned up")
-
-			// Kill any daemon that's running.
-			if err := daemon.StopDaemon(); err != nil {
-				return err
-			}
-			fmt.Println("daemon has been stopped")
-
-			return nil
-		},
-	}
-
 	debugCmd = &cobra.Command{
 		Use:   "debug",
 		Short: "Print debug information like config paths",
@@ -148,22 +99,13 @@ func init() {
 		"Program to run in new instances (e.g. 'aider --model ollama_chat/gemma3:1b')")
 	rootCmd.Flags().BoolVarP(&autoYesFlag, "autoyes", "y", false,
 		"[experimental] If enabled, all instances will automatically accept prompts")
-	rootCmd.Flags().BoolVar(&daemonFlag, "daemon", false, "Run a program that loads all sessions"+
-		" and runs autoyes mode on them.")
-
-	// Hide the daemonFlag as it's only for internal use
-	err := rootCmd.Flags().MarkHidden("daemon")
-	if err != nil {
-		panic(err)
-	}
-
 	rootCmd.AddCommand(debugCmd)
 	rootCmd.AddCommand(versionCmd)
-	rootCmd.AddCommand(resetCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
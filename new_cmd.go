@@ -0,0 +1,115 @@
+package main
+
+import (
+	"claude-squad/config"
+	"claude-squad/daemon"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/llm"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	newNameFlag    string
+	newPromptFlag  string
+	newProgramFlag string
+	newAutoYesFlag bool
+
+	newCmd = &cobra.Command{
+		Use:   "new",
+		Short: "Create a new instance in the current project without starting the UI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if newNameFlag == "" {
+				return fmt.Errorf("title cannot be empty")
+			}
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+
+			cfg := config.LoadConfig()
+			program := cfg.DefaultProgram
+			if newProgramFlag != "" {
+				program = newProgramFlag
+			}
+			autoYes := cfg.AutoYes || newAutoYesFlag
+
+			// Same as the UI: non-ASCII names are kept as the display name and translated into an
+			// ASCII title which is used for the branch and tmux session.
+			title := newNameFlag
+			if llm.HasNonASCII(newNameFlag) {
+				translatedID, err := llm.TranslateToEnglishID(newNameFlag)
+				if err != nil {
+					log.WarningLog.Printf("LLM translation failed: %v, using fallback", err)
+					translatedID = fmt.Sprintf("session-%d", time.Now().Unix())
+				}
+				title = translatedID
+			}
+
+			if err := session.ValidateTitle(title); err != nil {
+				return err
+			}
+			// Reject a taken title before any worktree or tmux session is created.
+			if err := projectManager.CheckTitleAvailable(title); err != nil {
+				return err
+			}
+
+			controller := daemon.NewController(projectManager)
+			defer controller.Close()
+			instance, err := controller.Create(session.InstanceOptions{
+				Title:       title,
+				DisplayName: newNameFlag,
+				Path:        ".",
+				Program:     program,
+				AutoYes:     autoYes,
+			})
+			if err != nil {
+				return err
+			}
+
+			if newPromptFlag != "" {
+				if err := session.SendInitialPrompt(instance, newPromptFlag); err != nil {
+					return fmt.Errorf("instance created but failed to send prompt: %w", err)
+				}
+			}
+
+			if autoYes && !controller.Connected() {
+				// The daemon accepts the prompts of the new instance. A running daemon picks it up by itself.
+				if err := daemon.EnsureRunning(); err != nil {
+					log.ErrorLog.Printf("failed to launch daemon: %v", err)
+				}
+			}
+
+			worktree, err := instance.GetGitWorktree()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Created instance '%s'\n", instance.Title)
+			fmt.Printf("branch:   %s\n", instance.Branch)
+			fmt.Printf("worktree: %s\n", worktree.GetWorktreePath())
+			return nil
+		},
+	}
+)
+
+func init() {
+	newCmd.Flags().StringVarP(&newNameFlag, "name", "n", "", "Name of the new instance")
+	newCmd.Flags().StringVar(&newPromptFlag, "prompt", "", "Prompt to send to the instance once it has started")
+	newCmd.Flags().StringVarP(&newProgramFlag, "program", "p", "",
+		"Program to run in the new instance (e.g. 'aider --model ollama_chat/gemma3:1b')")
+	newCmd.Flags().BoolVarP(&newAutoYesFlag, "autoyes", "y", false,
+		"[experimental] If enabled, the instance will automatically accept prompts")
+	if err := newCmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+
+	rootCmd.AddCommand(newCmd)
+}
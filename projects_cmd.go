@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// projectInfo is a project together with what is stored for it on disk.
+type projectInfo struct {
+	config.GlobalProjectData
+	RepoMissing bool   `json:"repo_missing"`
+	Instances   int    `json:"instances"`
+	DiskUsage   int64  `json:"disk_usage"`
+	ProjectDir  string `json:"project_dir"`
+	// Owner is the process owning the project's instances, nil if there is none.
+	Owner *session.LeaseHolder `json:"owner,omitempty"`
+}
+
+var (
+	projectsListJSONFlag bool
+	projectsRemoveForce  bool
+	projectsPruneDryRun  bool
+	projectsMaxRetries   int
+	projectsBackoff      int
+
+	projectsCmd = &cobra.Command{
+		Use:   "projects",
+		Short: "List, inspect, relink and remove projects",
+		Long: "List, inspect, relink and remove projects. A project is a git repository claude-squad has " +
+			"been used in. Projects can be referred to by ID, unique ID prefix, name or repository path.",
+	}
+
+	projectsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all projects",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			projects, err := instanceManager.GetAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to load projects: %w", err)
+			}
+			infos := make([]projectInfo, 0, len(projects))
+			for _, project := range projects {
+				infos = append(infos, loadProjectInfo(instanceManager, project))
+			}
+
+			if projectsListJSONFlag {
+				out, err := json.MarshalIndent(infos, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal projects: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tINSTANCES\tDISK\tREPO")
+			for _, info := range infos {
+				repo := info.RepoPath
+				if info.RepoMissing {
+					repo += " (missing)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", info.ID, info.Name, info.Instances, formatSize(info.DiskUsage), repo)
+			}
+			return w.Flush()
+		},
+	}
+
+	projectsShowCmd = &cobra.Command{
+		Use:   "show <project>",
+		Short: "Show the details and instances of a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			project, err := resolveProject(instanceManager, args[0])
+			if err != nil {
+				return err
+			}
+			info := loadProjectInfo(instanceManager, project)
+
+			repo := info.RepoPath
+			if info.RepoMissing {
+				repo += " (missing)"
+			}
+			fmt.Printf("ID:         %s\n", info.ID)
+			fmt.Printf("Name:       %s\n", info.Name)
+			fmt.Printf("Repository: %s\n", repo)
+			fmt.Printf("Directory:  %s\n", info.ProjectDir)
+			fmt.Printf("Created:    %s\n", info.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("Disk usage: %s\n", formatSize(info.DiskUsage))
+			fmt.Printf("Instances:  %d\n", info.Instances)
+			fmt.Printf("Restarts:   %s\n", formatRestartPolicy(info.RestartPolicy))
+			if info.Owner != nil {
+				fmt.Printf("Owner:      %s (PID %d)\n", orDash(info.Owner.Owner), info.Owner.PID)
+			} else {
+				fmt.Printf("Owner:      none\n")
+			}
+
+			data, err := instanceManager.GetProjectManager(project.ID, project.RepoPath).GetAllInstancesData()
+			if err != nil {
+				return fmt.Errorf("failed to load instances: %w", err)
+			}
+			if len(data) == 0 {
+				return nil
+			}
+			fmt.Println()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TITLE\tSTATUS\tBRANCH\tAGE")
+			for _, d := range data {
+				status := d.Status.String()
+				if d.Status == session.Error && d.ErrorReason != "" {
+					status = fmt.Sprintf("error (%s)", d.ErrorReason)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Title, status, d.Branch, formatAge(d.CreatedAt))
+			}
+			return w.Flush()
+		},
+	}
+
+	projectsRemoveCmd = &cobra.Command{
+		Use:   "remove <project>",
+		Short: "Kill all instances of a project and forget it",
+		Long: "Kill all instances of a project, including their worktrees, branches and tmux sessions, " +
+			"delete the project directory and remove the project from the project list. The repository " +
+			"itself is not touched. The daemon or a UI owning the project has to be stopped first.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			project, err := resolveProject(instanceManager, args[0])
+			if err != nil {
+				return err
+			}
+			info := loadProjectInfo(instanceManager, project)
+			lease, err := acquireProjectLease(instanceManager.GetProjectManager(project.ID, project.RepoPath))
+			if err != nil {
+				return fmt.Errorf("cannot remove project %s: %w", project.Name, err)
+			}
+			defer lease.Release()
+			if !projectsRemoveForce && info.Instances > 0 {
+				fmt.Printf("Remove project %s and kill its %d instances? [y/N] ", project.Name, info.Instances)
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			if err := instanceManager.RemoveProject(project.ID); err != nil {
+				return err
+			}
+			fmt.Printf("Removed project %s (%d instances, %s)\n", project.Name, info.Instances, formatSize(info.DiskUsage))
+			return nil
+		},
+	}
+
+	projectsRelinkCmd = &cobra.Command{
+		Use:   "relink <project> <repo-path>",
+		Short: "Point a project at the new location of its repository",
+		Long: "Point a project at the new location of its repository after the repository was moved, " +
+			"keeping its instances. The project gets a new ID since IDs are derived from the path.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			project, err := resolveProject(instanceManager, args[0])
+			if err != nil {
+				return err
+			}
+			relinked, err := instanceManager.RelinkProject(project.ID, args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Relinked project %s to %s, new ID %s\n", project.Name, relinked.RepoPath, relinked.ID)
+			return nil
+		},
+	}
+
+	projectsRestartPolicyCmd = &cobra.Command{
+		Use:   "restart-policy <project>",
+		Short: "Show or set whether dead sessions of a project are restarted",
+		Long: "Show or set whether the sessions of a project's instances are restarted when they die, e.g. " +
+			"because the program crashed. The daemon, or the UI if the daemon isn't running, restarts a " +
+			"dead session right away, then waits --backoff seconds before the next restart, doubling the " +
+			"wait every time, and gives up after --max-retries restarts in a row. --max-retries 0 " +
+			"disables restarts, dead sessions are only reported then.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			project, err := resolveProject(instanceManager, args[0])
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("max-retries") && !cmd.Flags().Changed("backoff") {
+				fmt.Printf("Restarts of %s: %s\n", project.Name, formatRestartPolicy(project.RestartPolicy))
+				return nil
+			}
+
+			policy := config.RestartPolicy{Backoff: config.DefaultRestartBackoff}
+			if project.RestartPolicy != nil {
+				policy = *project.RestartPolicy
+			}
+			if cmd.Flags().Changed("max-retries") {
+				policy.MaxRetries = projectsMaxRetries
+			}
+			if cmd.Flags().Changed("backoff") {
+				policy.Backoff = projectsBackoff
+			}
+			if err := instanceManager.SetRestartPolicy(project.ID, &policy); err != nil {
+				return err
+			}
+			fmt.Printf("Restarts of %s: %s\n", project.Name, formatRestartPolicy(&policy))
+			return nil
+		},
+	}
+
+	projectsPruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove projects whose repository no longer exists",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			projects, err := instanceManager.GetAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to load projects: %w", err)
+			}
+
+			pruned, failed := 0, 0
+			for _, project := range projects {
+				info := loadProjectInfo(instanceManager, project)
+				if !info.RepoMissing {
+					continue
+				}
+				if projectsPruneDryRun {
+					fmt.Printf("%s (%s): would be removed, %d instances, %s\n",
+						project.Name, project.RepoPath, info.Instances, formatSize(info.DiskUsage))
+					pruned++
+					continue
+				}
+				if err := removeProject(instanceManager, project); err != nil {
+					failed++
+					log.ErrorLog.Printf("failed to prune project %s: %v", project.ID, err)
+					fmt.Printf("%s (%s): failed: %v\n", project.Name, project.RepoPath, err)
+					continue
+				}
+				pruned++
+				fmt.Printf("%s (%s): removed\n", project.Name, project.RepoPath)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d projects could not be removed", failed, pruned+failed)
+			}
+			if pruned == 0 {
+				fmt.Println("All project repositories exist")
+			}
+			return nil
+		},
+	}
+)
+
+// removeProject removes a project while holding its lease.
+func removeProject(instanceManager *session.InstanceManager, project config.GlobalProjectData) error {
+	lease, err := acquireProjectLease(instanceManager.GetProjectManager(project.ID, project.RepoPath))
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+	return instanceManager.RemoveProject(project.ID)
+}
+
+// loadProjectInfo collects what is stored on disk for a project. Instances are counted from the
+// project state rather than the cached count in the global state.
+func loadProjectInfo(instanceManager *session.InstanceManager, project config.GlobalProjectData) projectInfo {
+	projectManager := instanceManager.GetProjectManager(project.ID, project.RepoPath)
+	info := projectInfo{
+		GlobalProjectData: project,
+		DiskUsage:         projectManager.WorktreesDiskUsage(),
+		ProjectDir:        projectManager.GetProjectDir(),
+	}
+	if _, err := os.Stat(project.RepoPath); os.IsNotExist(err) {
+		info.RepoMissing = true
+	}
+	if owner, err := projectManager.LeaseHolder(); err == nil {
+		info.Owner = owner
+	} else {
+		log.WarningLog.Printf("failed to check the owner of project %s: %v", project.ID, err)
+	}
+	if data, err := projectManager.GetAllInstancesData(); err == nil {
+		info.Instances = len(data)
+	} else {
+		log.WarningLog.Printf("failed to load instances of project %s: %v", project.ID, err)
+		info.Instances = project.InstanceCount
+	}
+	return info
+}
+
+// formatRestartPolicy describes a restart policy for humans.
+func formatRestartPolicy(policy *config.RestartPolicy) string {
+	if !policy.Enabled() {
+		return "disabled"
+	}
+	return fmt.Sprintf("up to %d in a row, backing off from %s", policy.MaxRetries, policy.Delay(1))
+}
+
+// resolveProject finds a project by ID, unique ID prefix, unique name or repository path.
+func resolveProject(instanceManager *session.InstanceManager, ref string) (config.GlobalProjectData, error) {
+	projects, err := instanceManager.GetAllProjects()
+	if err != nil {
+		return config.GlobalProjectData{}, fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	absRef, _ := filepath.Abs(ref)
+	var byPrefix, byName []config.GlobalProjectData
+	for _, project := range projects {
+		if project.ID == ref || project.RepoPath == absRef {
+			return project, nil
+		}
+		if strings.HasPrefix(project.ID, ref) {
+			byPrefix = append(byPrefix, project)
+		}
+		if project.Name == ref {
+			byName = append(byName, project)
+		}
+	}
+	for _, matches := range [][]config.GlobalProjectData{byPrefix, byName} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			ids := make([]string, 0, len(matches))
+			for _, project := range matches {
+				ids = append(ids, project.ID)
+			}
+			return config.GlobalProjectData{}, fmt.Errorf("%q matches several projects: %s", ref, strings.Join(ids, ", "))
+		}
+	}
+	return config.GlobalProjectData{}, fmt.Errorf("project not found: %s", ref)
+}
+
+func init() {
+	projectsListCmd.Flags().BoolVar(&projectsListJSONFlag, "json", false, "Print projects as JSON")
+	projectsRemoveCmd.Flags().BoolVarP(&projectsRemoveForce, "force", "f", false, "Do not ask for confirmation")
+	projectsRestartPolicyCmd.Flags().IntVar(&projectsMaxRetries, "max-retries", 0,
+		"Number of restarts in a row after which a dead session is left alone, 0 disables restarts")
+	projectsRestartPolicyCmd.Flags().IntVar(&projectsBackoff, "backoff", config.DefaultRestartBackoff,
+		"Seconds to wait before the second restart, doubled for every further restart")
+	projectsPruneCmd.Flags().BoolVar(&projectsPruneDryRun, "dry-run", false, "Only report the projects which would be removed")
+
+	projectsCmd.AddCommand(projectsListCmd)
+	projectsCmd.AddCommand(projectsShowCmd)
+	projectsCmd.AddCommand(projectsRelinkCmd)
+	projectsCmd.AddCommand(projectsRemoveCmd)
+	projectsCmd.AddCommand(projectsRestartPolicyCmd)
+	projectsCmd.AddCommand(projectsPruneCmd)
+
+	rootCmd.AddCommand(projectsCmd)
+}
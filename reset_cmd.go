@@ -0,0 +1,187 @@
+package main
+
+import (
+	cmd2 "claude-squad/cmd"
+	"claude-squad/config"
+	"claude-squad/daemon"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	resetProjectFlag string
+	resetAllFlag     bool
+	resetDryRunFlag  bool
+
+	resetCmd = &cobra.Command{
+		Use:   "reset",
+		Short: "Delete all instances of the current project",
+		Long: "Delete all instances of the current project, including their tmux sessions, worktrees and " +
+			"branches. Use --project to reset another project. The daemon or a UI owning the project has to " +
+			"be stopped first.\n\n" +
+			"With --all, every project is reset, and all remaining claude-squad tmux sessions, legacy " +
+			"instances and worktrees are removed and the daemon is stopped.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			if resetAllFlag && resetProjectFlag != "" {
+				return fmt.Errorf("--project and --all cannot be combined")
+			}
+
+			instanceManager, err := newInstanceManager()
+			if err != nil {
+				return err
+			}
+			var projects []config.GlobalProjectData
+			switch {
+			case resetAllFlag:
+				projects, err = instanceManager.GetAllProjects()
+				if err != nil {
+					return fmt.Errorf("failed to load projects: %w", err)
+				}
+			case resetProjectFlag != "":
+				project, err := resolveProject(instanceManager, resetProjectFlag)
+				if err != nil {
+					return err
+				}
+				projects = append(projects, project)
+			default:
+				projectManager, err := currentProjectManager()
+				if err != nil {
+					return err
+				}
+				project, err := projectManager.GetProjectData()
+				if err != nil {
+					return fmt.Errorf("failed to load project: %w", err)
+				}
+				if project == nil {
+					return fmt.Errorf("project %s is not registered", projectManager.GetProjectID())
+				}
+				projects = append(projects, *project)
+			}
+
+			// The daemon owns the instances of all projects. --all stops it anyway, so it is stopped
+			// before the projects are reset rather than after.
+			if resetAllFlag && !resetDryRunFlag {
+				if err := daemon.StopDaemon(); err != nil {
+					return err
+				}
+				fmt.Println("daemon has been stopped")
+			}
+			for _, project := range projects {
+				if err := resetProject(instanceManager, project, resetDryRunFlag); err != nil {
+					return err
+				}
+			}
+			if resetAllFlag {
+				return resetGlobal(resetDryRunFlag)
+			}
+			return nil
+		},
+	}
+)
+
+// resetProject deletes all instances of a project, or only prints what would be deleted.
+func resetProject(instanceManager *session.InstanceManager, project config.GlobalProjectData, dryRun bool) error {
+	projectManager := instanceManager.GetProjectManager(project.ID, project.RepoPath)
+	instances, err := projectManager.GetAllInstancesData()
+	if err != nil {
+		return fmt.Errorf("failed to load instances of project %s: %w", project.Name, err)
+	}
+
+	if !dryRun {
+		// Otherwise the owner of the project, e.g. the daemon, could restart or store the instances
+		// while they are deleted.
+		lease, err := acquireProjectLease(projectManager)
+		if err != nil {
+			return fmt.Errorf("cannot reset project %s: %w", project.Name, err)
+		}
+		defer lease.Release()
+		if err := projectManager.DeleteAllInstances(); err != nil {
+			return fmt.Errorf("failed to reset project %s: %w", project.Name, err)
+		}
+		fmt.Printf("Project %s has been reset, %d instances deleted\n", project.Name, len(instances))
+		return nil
+	}
+
+	fmt.Printf("Project %s (%s) would be reset:\n", project.Name, project.RepoPath)
+	for _, d := range instances {
+		fmt.Printf("  instance %s: tmux session %s, branch %s, worktree %s\n",
+			d.Title, tmux.SessionName(d.Title), d.Worktree.BranchName, d.Worktree.WorktreePath)
+	}
+	fmt.Printf("  everything in %s (%s)\n", filepath.Join(projectManager.GetProjectDir(), session.ProjectWorktreesDirName),
+		formatSize(projectManager.WorktreesDiskUsage()))
+	return nil
+}
+
+// resetGlobal removes what is not tied to a project: the remaining tmux sessions and the legacy
+// instances and worktrees. Without dryRun, the daemon has been stopped already.
+func resetGlobal(dryRun bool) error {
+	cmdExec := cmd2.MakeExecutor()
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	legacyWorktrees := filepath.Join(configDir, "worktrees")
+	_, statErr := os.Stat(legacyWorktrees)
+	hasLegacyWorktrees := statErr == nil
+
+	if dryRun {
+		sessions, err := tmux.ListSessions(cmdExec)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Also removed with --all:")
+		for _, s := range sessions {
+			fmt.Printf("  tmux session %s\n", s.Name)
+		}
+		fmt.Println("  legacy instances stored in state.json")
+		if hasLegacyWorktrees {
+			fmt.Printf("  legacy worktrees in %s\n", legacyWorktrees)
+		}
+		if status, err := daemon.GetStatus(); err == nil && status.Running {
+			fmt.Printf("  daemon with PID %d\n", status.PID)
+		}
+		return nil
+	}
+
+	storage, err := session.NewStorage(config.LoadState())
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	if err := storage.DeleteAllInstances(); err != nil {
+		return fmt.Errorf("failed to reset legacy storage: %w", err)
+	}
+	fmt.Println("Legacy storage has been reset")
+
+	if err := tmux.CleanupSessions(cmdExec); err != nil {
+		return fmt.Errorf("failed to cleanup tmux sessions: %w", err)
+	}
+	fmt.Println("Tmux sessions have been cleaned up")
+
+	if hasLegacyWorktrees {
+		if err := git.CleanupWorktrees(); err != nil {
+			return fmt.Errorf("failed to cleanup worktrees: %w", err)
+		}
+		fmt.Println("Legacy worktrees have been cleaned up")
+	}
+	return nil
+}
+
+func init() {
+	resetCmd.Flags().StringVar(&resetProjectFlag, "project", "",
+		"Reset the given project (ID, ID prefix, name or repository path) instead of the current one")
+	resetCmd.Flags().BoolVar(&resetAllFlag, "all", false, "Reset all projects and remove all claude-squad tmux sessions")
+	resetCmd.Flags().BoolVar(&resetDryRunFlag, "dry-run", false, "Only print what would be deleted")
+
+	rootCmd.AddCommand(resetCmd)
+}
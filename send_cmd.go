@@ -0,0 +1,104 @@
+package main
+
+import (
+	"claude-squad/daemon"
+	"claude-squad/log"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sendStdinFlag bool
+	sendFileFlag  string
+
+	sendCmd = &cobra.Command{
+		Use:   "send <title> [prompt]",
+		Short: "Send a prompt to a running instance",
+		Long: "Send a prompt to a running instance of the current project. The prompt is read from the " +
+			"second argument, from stdin with --stdin or from a file with --file.\n\n" +
+			"The prompt is delivered through the tmux server, so this is safe to use while the UI is open. " +
+			"An instance which was paused for being idle is resumed first if idle_pause.auto_resume is set.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			prompt, err := readPromptArg(args[1:], sendStdinFlag, sendFileFlag)
+			if err != nil {
+				return err
+			}
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			instance, err := projectManager.GetInstanceDetached(args[0])
+			if err != nil {
+				return err
+			}
+			controller := daemon.NewController(projectManager)
+			defer controller.Close()
+			resumed, err := controller.Send(instance, prompt)
+			if resumed {
+				fmt.Printf("Resumed '%s', it was paused for being idle\n", instance.Title)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Sent prompt to '%s'\n", instance.Title)
+			return nil
+		},
+	}
+)
+
+// readPromptArg returns the prompt from exactly one of the positional args, stdin or a file.
+func readPromptArg(args []string, fromStdin bool, fromFile string) (string, error) {
+	sources := 0
+	if len(args) > 0 {
+		sources++
+	}
+	if fromStdin {
+		sources++
+	}
+	if fromFile != "" {
+		sources++
+	}
+	if sources != 1 {
+		return "", fmt.Errorf("provide the prompt either as an argument, with --stdin or with --file")
+	}
+
+	var prompt string
+	switch {
+	case fromStdin:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt from stdin: %w", err)
+		}
+		prompt = string(data)
+	case fromFile != "":
+		data, err := os.ReadFile(fromFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		prompt = string(data)
+	default:
+		prompt = args[0]
+	}
+
+	prompt = strings.TrimRight(prompt, "\r\n")
+	if strings.TrimSpace(prompt) == "" {
+		return "", fmt.Errorf("prompt cannot be empty")
+	}
+	return prompt, nil
+}
+
+func init() {
+	sendCmd.Flags().BoolVar(&sendStdinFlag, "stdin", false, "Read the prompt from stdin")
+	sendCmd.Flags().StringVarP(&sendFileFlag, "file", "f", "", "Read the prompt from a file")
+
+	rootCmd.AddCommand(sendCmd)
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"claude-squad/log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditFileName is the name of the file in the config directory which records every prompt that
+// was accepted without a human, one JSON object per line.
+const AuditFileName = "audit.jsonl"
+
+// auditSource names the process which accepts prompts in the audit log.
+var auditSource string
+
+// SetAuditSource sets the name the current process is recorded with in the audit log, e.g.
+// "daemon" or "ui".
+func SetAuditSource(source string) {
+	auditSource = source
+}
+
+// AuditRecord records a prompt which was accepted in AutoYes mode.
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	ProjectID string    `json:"project_id,omitempty"`
+	RepoPath  string    `json:"repo_path,omitempty"`
+	Instance  string    `json:"instance"`
+	Program   string    `json:"program"`
+	// Prompt is the prompt as it was shown, e.g. the command the program asked to run.
+	Prompt string `json:"prompt"`
+	// Source is the process which accepted the prompt, e.g. "daemon" or "ui".
+	Source string `json:"source,omitempty"`
+	PID    int    `json:"pid"`
+}
+
+// AuditFilter selects audit records. Zero fields match every record.
+type AuditFilter struct {
+	Instance  string
+	ProjectID string
+	Since     time.Time
+}
+
+func (f AuditFilter) matches(record AuditRecord) bool {
+	return (f.Instance == "" || record.Instance == f.Instance) &&
+		(f.ProjectID == "" || record.ProjectID == f.ProjectID) &&
+		(f.Since.IsZero() || !record.Time.Before(f.Since))
+}
+
+// AuditFilePath returns the path of the audit log.
+func AuditFilePath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, AuditFileName), nil
+}
+
+// recordApproval appends a record of a prompt the instance accepted to the audit log. Failures are
+// logged, they must not stop AutoYes mode.
+func recordApproval(instance *Instance, prompt string) {
+	record := AuditRecord{
+		Time:      time.Now(),
+		ProjectID: instance.ProjectID,
+		Instance:  instance.Title,
+		Program:   instance.Program,
+		Prompt:    prompt,
+		Source:    auditSource,
+		PID:       os.Getpid(),
+	}
+	if instance.gitWorktree != nil {
+		record.RepoPath = instance.gitWorktree.GetRepoPath()
+	}
+	if err := appendAuditRecord(record); err != nil {
+		log.ErrorLog.Printf("failed to record accepted prompt of %s in the audit log: %v", instance.Title, err)
+	}
+}
+
+func appendAuditRecord(record AuditRecord) error {
+	path, err := AuditFilePath()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	// A single write keeps records of concurrent processes from interleaving.
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadAuditRecords returns the records of the audit log matching the filter, oldest first. Lines
+// which can't be parsed are skipped with a warning.
+func ReadAuditRecords(filter AuditFilter) ([]AuditRecord, error) {
+	path, err := AuditFilePath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			log.WarningLog.Printf("skipping line %d of the audit log: %v", lineNumber, err)
+			continue
+		}
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return records, nil
+}
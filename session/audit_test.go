@@ -0,0 +1,59 @@
+package session
+
+import (
+	"claude-squad/log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+	t.Setenv("HOME", t.TempDir())
+
+	records, err := ReadAuditRecords(AuditFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	now := time.Now()
+	require.NoError(t, appendAuditRecord(AuditRecord{Time: now.Add(-2 * time.Hour), ProjectID: "p1", Instance: "a", Prompt: "ls"}))
+	require.NoError(t, appendAuditRecord(AuditRecord{Time: now.Add(-time.Minute), ProjectID: "p1", Instance: "b", Prompt: "go test"}))
+	require.NoError(t, appendAuditRecord(AuditRecord{Time: now, ProjectID: "p2", Instance: "a", Prompt: "make"}))
+
+	path, err := AuditFilePath()
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Broken lines are skipped.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("{not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	records, err = ReadAuditRecords(AuditFilter{})
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	records, err = ReadAuditRecords(AuditFilter{Instance: "a"})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "ls", records[0].Prompt)
+
+	records, err = ReadAuditRecords(AuditFilter{Since: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = ReadAuditRecords(AuditFilter{Instance: "a", ProjectID: "p2"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "make", records[0].Prompt)
+	assert.Equal(t, filepath.Join(os.Getenv("HOME"), ".claude-squad", AuditFileName), path)
+}
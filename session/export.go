@@ -0,0 +1,317 @@
+package session
+
+import (
+	"archive/tar"
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ExportFormatVersion is the version of the export archive format written by ExportInstance.
+const ExportFormatVersion = 1
+
+// Names of the files in an export archive
+const (
+	exportManifestFile = "manifest.json"
+	exportBundleFile   = "branch.bundle"
+	exportHistoryFile  = "history.txt"
+)
+
+// ExportManifest describes the instance in an export archive.
+type ExportManifest struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	// Commits is the number of commits on the instance branch since its base commit. The archive
+	// only contains a git bundle if there are any.
+	Commits int `json:"commits"`
+	// HasHistory is set if the archive contains the pane history of the instance.
+	HasHistory bool         `json:"has_history"`
+	Instance   InstanceData `json:"instance"`
+}
+
+// ExportInstance writes a gzipped tar archive of an instance to w. The archive contains the
+// instance's metadata, its branch as a git bundle of the commits since the base commit, and the
+// pane history if its tmux session is running. Uncommitted changes of a running instance are
+// committed first, so that they are part of the bundle.
+func (pm *ProjectInstanceManager) ExportInstance(title string, w io.Writer) (*ExportManifest, error) {
+	instance, err := pm.GetInstanceDetached(title)
+	if err != nil {
+		return nil, err
+	}
+	worktree := instance.gitWorktree
+	if worktree.GetBaseCommitSHA() == "" {
+		return nil, fmt.Errorf("instance '%s' has no base commit", title)
+	}
+
+	var history string
+	if !instance.Paused() {
+		if _, err := os.Stat(worktree.GetWorktreePath()); err == nil {
+			commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s (exported)", instance.Title, time.Now().Format(time.RFC822))
+			if err := worktree.CommitChanges(commitMsg); err != nil {
+				return nil, err
+			}
+			if err := instance.UpdateDiffStats(); err != nil {
+				log.WarningLog.Printf("failed to update diff stats of %s: %v", title, err)
+			}
+		}
+		if instance.TmuxAlive() {
+			if history, err = instance.PreviewFullHistory(); err != nil {
+				log.WarningLog.Printf("failed to capture history of %s: %v", title, err)
+			}
+		}
+	}
+
+	commits, err := git.CountCommits(worktree.GetRepoPath(), worktree.GetBaseCommitSHA(), worktree.GetBranchName())
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ExportManifest{
+		Version:    ExportFormatVersion,
+		ExportedAt: time.Now(),
+		Commits:    commits,
+		HasHistory: history != "",
+		Instance:   instance.ToInstanceData(),
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeTarFile(tw, exportManifestFile, manifestData); err != nil {
+		return nil, err
+	}
+	if commits > 0 {
+		tmpDir, err := os.MkdirTemp("", "claude-squad-export-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		bundlePath := filepath.Join(tmpDir, exportBundleFile)
+		if err := git.CreateBundle(worktree.GetRepoPath(), bundlePath, worktree.GetBaseCommitSHA(), worktree.GetBranchName()); err != nil {
+			return nil, err
+		}
+		bundle, err := os.ReadFile(bundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if err := writeTarFile(tw, exportBundleFile, bundle); err != nil {
+			return nil, err
+		}
+	}
+	if history != "" {
+		if err := writeTarFile(tw, exportHistoryFile, []byte(history)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// ImportInstance recreates an instance from an export archive as a paused instance of the project.
+// The instance branch is recreated from the bundle under this machine's branch prefix, and its
+// worktree is set up when the instance is resumed. The base commit of the instance has to be
+// present in the repository. If title is empty, the exported title is used.
+func (pm *ProjectInstanceManager) ImportInstance(r io.Reader, title string) (*Instance, *ExportManifest, error) {
+	tmpDir, err := os.MkdirTemp("", "claude-squad-import-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	bundlePath := filepath.Join(tmpDir, exportBundleFile)
+
+	manifest, history, err := readExportArchive(r, bundlePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := manifest.Instance
+	if title == "" {
+		title = data.Title
+	}
+	// The title comes from a foreign archive and names files, the branch and the tmux session.
+	if err := ValidateTitle(title); err != nil {
+		return nil, nil, err
+	}
+	baseCommitSHA := data.Worktree.BaseCommitSHA
+
+	instances, err := pm.GetAllInstancesData()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load instances: %w", err)
+	}
+	if len(instances) >= ProjectInstanceLimit {
+		return nil, nil, fmt.Errorf("project instance limit reached: maximum %d instances allowed", ProjectInstanceLimit)
+	}
+	if err := checkTitleAvailable(instances, title); err != nil {
+		return nil, nil, fmt.Errorf("%w, import it under a different title", err)
+	}
+	if !git.CommitExists(pm.repoPath, baseCommitSHA) {
+		return nil, nil, fmt.Errorf("base commit %s of the instance is not in the repository, fetch it first", baseCommitSHA)
+	}
+
+	worktree, branch, err := git.NewGitWorktreeForProject(pm.repoPath, title, pm.projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if git.BranchExists(worktree.GetRepoPath(), branch) {
+		return nil, nil, fmt.Errorf("branch %s already exists", branch)
+	}
+	if manifest.Commits > 0 {
+		err = git.FetchBundle(worktree.GetRepoPath(), bundlePath, data.Worktree.BranchName, branch)
+	} else {
+		err = git.CreateBranch(worktree.GetRepoPath(), branch, baseCommitSHA)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if data.DisplayName == "" || data.DisplayName == data.Title {
+		data.DisplayName = title
+	}
+	data.Title = title
+	data.Path = worktree.GetRepoPath()
+	data.Branch = branch
+	data.Status = Paused
+	data.ProjectID = pm.projectID
+	// Settings and state of the exporting machine don't carry over: auto-yes has to be enabled
+	// again explicitly, and the schedule and errors belong to the other daemon.
+	data.AutoYes = false
+	data.ScheduledBy = ""
+	data.ErrorReason = ""
+	data.AutoPaused = false
+	data.Worktree = GitWorktreeData{
+		RepoPath:      worktree.GetRepoPath(),
+		WorktreePath:  worktree.GetWorktreePath(),
+		SessionName:   title,
+		BranchName:    branch,
+		BaseCommitSHA: baseCommitSHA,
+	}
+	instance := FromInstanceDataDetached(data)
+
+	if err := pm.projectStorage.AddInstance(instance.ToInstanceData()); err != nil {
+		if deleteErr := git.DeleteBranch(worktree.GetRepoPath(), branch); deleteErr != nil {
+			log.ErrorLog.Printf("failed to delete branch %s after failed import: %v", branch, deleteErr)
+		}
+		return nil, nil, fmt.Errorf("failed to save instance: %w", err)
+	}
+	if err := pm.globalManager.UpdateProjectInstanceCount(pm.projectID, len(instances)+1); err != nil {
+		log.WarningLog.Printf("Failed to update project instance count: %v", err)
+	}
+
+	if history != nil {
+		if err := pm.saveImportedHistory(title, history); err != nil {
+			log.WarningLog.Printf("failed to save history of %s: %v", title, err)
+		}
+	}
+	return instance, manifest, nil
+}
+
+// ImportedHistory returns the pane history an instance was exported with, or an error wrapping
+// os.ErrNotExist if the instance was not imported or exported without history.
+func (pm *ProjectInstanceManager) ImportedHistory(title string) (string, error) {
+	history, err := os.ReadFile(pm.importedHistoryPath(title))
+	if err != nil {
+		return "", err
+	}
+	return string(history), nil
+}
+
+func (pm *ProjectInstanceManager) importedHistoryPath(title string) string {
+	return filepath.Join(pm.projectStorage.GetProjectHistoryDir(), title+".txt")
+}
+
+func (pm *ProjectInstanceManager) saveImportedHistory(title string, history []byte) error {
+	if err := os.MkdirAll(pm.projectStorage.GetProjectHistoryDir(), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(pm.importedHistoryPath(title), history, 0644)
+}
+
+// readExportArchive reads an export archive, writing the git bundle, if any, to bundlePath.
+func readExportArchive(r io.Reader, bundlePath string) (*ExportManifest, []byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *ExportManifest
+	var history []byte
+	hasBundle := false
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		switch header.Name {
+		case exportManifestFile:
+			manifest = &ExportManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+			}
+		case exportBundleFile:
+			f, err := os.Create(bundlePath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to write bundle: %w", err)
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to write bundle: %w", err)
+			}
+			hasBundle = true
+		case exportHistoryFile:
+			if history, err = io.ReadAll(tr); err != nil {
+				return nil, nil, fmt.Errorf("failed to read history: %w", err)
+			}
+		}
+	}
+
+	switch {
+	case manifest == nil:
+		return nil, nil, fmt.Errorf("archive has no %s, is it a claude-squad export?", exportManifestFile)
+	case manifest.Version > ExportFormatVersion:
+		return nil, nil, fmt.Errorf("archive has format version %d, this version of claude-squad supports up to %d",
+			manifest.Version, ExportFormatVersion)
+	case manifest.Instance.Title == "" || manifest.Instance.Worktree.BaseCommitSHA == "":
+		return nil, nil, fmt.Errorf("archive manifest is missing the instance title or base commit")
+	case manifest.Commits > 0 && !hasBundle:
+		return nil, nil, fmt.Errorf("archive has no %s", exportBundleFile)
+	}
+	return manifest, history, nil
+}
+
+// writeTarFile adds a regular file with the given content to an archive.
+func writeTarFile(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package session
+
+import (
+	"claude-squad/cmd"
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OrphanKind is the type of resource an Orphan refers to
+type OrphanKind string
+
+const (
+	OrphanWorktree    OrphanKind = "worktree"
+	OrphanBranch      OrphanKind = "branch"
+	OrphanTmuxSession OrphanKind = "tmux-session"
+)
+
+// orphanGracePeriod protects resources of instances which are being created right now and have not
+// been written to storage yet.
+const orphanGracePeriod = time.Minute
+
+// Orphan is a resource created by claude-squad which no stored instance owns
+type Orphan struct {
+	Kind OrphanKind `json:"kind"`
+	// Name is the worktree path, branch name or tmux session name
+	Name string `json:"name"`
+	// RepoPath is the repository a worktree or branch belongs to. It's empty for tmux sessions and
+	// for worktree directories which no known repository has registered.
+	RepoPath string `json:"repo_path,omitempty"`
+	// Size is the disk usage of a worktree in bytes
+	Size int64 `json:"size,omitempty"`
+}
+
+// ownedResources holds the resources referenced by stored instances
+type ownedResources struct {
+	worktrees map[string]bool
+	// branches maps repository paths to the branches used by instances of that repository
+	branches map[string]map[string]bool
+	sessions map[string]bool
+	repos    map[string]bool
+}
+
+// loadOwnedResources collects the resources of every instance in every project state file and in
+// the legacy state. Any unreadable state file is an error, since it could own anything.
+func (im *InstanceManager) loadOwnedResources() (*ownedResources, error) {
+	owned := &ownedResources{
+		worktrees: make(map[string]bool),
+		branches:  make(map[string]map[string]bool),
+		sessions:  make(map[string]bool),
+		repos:     make(map[string]bool),
+	}
+	addInstance := func(data InstanceData) {
+		owned.sessions[tmux.SessionName(data.Title)] = true
+		if data.Worktree.WorktreePath != "" {
+			owned.worktrees[canonicalPath(data.Worktree.WorktreePath)] = true
+		}
+		if data.Worktree.RepoPath != "" {
+			repo := canonicalPath(data.Worktree.RepoPath)
+			owned.repos[repo] = true
+			if owned.branches[repo] == nil {
+				owned.branches[repo] = make(map[string]bool)
+			}
+			owned.branches[repo][data.Worktree.BranchName] = true
+		}
+	}
+
+	projects, err := im.GetAllProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load projects: %w", err)
+	}
+	for _, project := range projects {
+		owned.repos[canonicalPath(project.RepoPath)] = true
+	}
+
+	statePaths, err := filepath.Glob(filepath.Join(im.configDir, ProjectsDirName, "*", ProjectStateFileName))
+	if err != nil {
+		return nil, err
+	}
+	for _, statePath := range statePaths {
+		content, err := os.ReadFile(statePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", statePath, err)
+		}
+		var state ProjectState
+		if err := json.Unmarshal(content, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", statePath, err)
+		}
+		if state.Project.RepoPath != "" {
+			owned.repos[canonicalPath(state.Project.RepoPath)] = true
+		}
+		for _, data := range state.Instances {
+			addInstance(data)
+		}
+	}
+
+	legacyInstances := config.LoadState().GetInstances()
+	if len(legacyInstances) > 0 {
+		var legacy []InstanceData
+		if err := json.Unmarshal(legacyInstances, &legacy); err != nil {
+			return nil, fmt.Errorf("failed to parse legacy instances: %w", err)
+		}
+		for _, data := range legacy {
+			addInstance(data)
+		}
+	}
+
+	return owned, nil
+}
+
+// FindOrphans returns the worktrees, branches and tmux sessions created by claude-squad which no
+// stored instance of any project owns.
+//
+// Only worktrees inside the claude-squad config directory are considered. Branches are reported
+// if they were checked out in an orphaned worktree. If includeBranches is set, every other branch
+// starting with branchPrefix which no instance owns is reported as well.
+func (im *InstanceManager) FindOrphans(branchPrefix string, includeBranches bool) ([]Orphan, error) {
+	owned, err := im.loadOwnedResources()
+	if err != nil {
+		return nil, err
+	}
+
+	managedRoots := []string{
+		canonicalPath(filepath.Join(im.configDir, "worktrees")),
+		canonicalPath(filepath.Join(im.configDir, ProjectsDirName)),
+	}
+	isManaged := func(path string) bool {
+		for _, root := range managedRoots {
+			if strings.HasPrefix(path, root+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var worktrees, branches []Orphan
+	registered := make(map[string]bool)
+	for repo := range owned.repos {
+		entries, err := git.ListWorktrees(repo)
+		if err != nil {
+			// The repository may have been deleted or moved, its worktree directories are picked
+			// up below.
+			log.WarningLog.Printf("gc: skipping repository %s: %v", repo, err)
+			continue
+		}
+
+		checkedOut := make(map[string]bool)
+		orphanBranches := make(map[string]bool)
+		for _, entry := range entries {
+			path := canonicalPath(entry.Path)
+			registered[path] = true
+			if !isManaged(path) || owned.worktrees[path] || isRecent(path) {
+				checkedOut[entry.Branch] = true
+				continue
+			}
+			worktrees = append(worktrees, Orphan{Kind: OrphanWorktree, Name: entry.Path, RepoPath: repo, Size: diskUsage(path)})
+			if entry.Branch != "" && !owned.branches[repo][entry.Branch] {
+				orphanBranches[entry.Branch] = true
+			}
+		}
+
+		prefixBranches, err := git.ListBranches(repo, branchPrefix)
+		if err != nil {
+			log.WarningLog.Printf("gc: failed to list branches of %s: %v", repo, err)
+			continue
+		}
+		for _, branch := range prefixBranches {
+			if owned.branches[repo][branch] || checkedOut[branch] {
+				continue
+			}
+			if orphanBranches[branch] || includeBranches {
+				branches = append(branches, Orphan{Kind: OrphanBranch, Name: branch, RepoPath: repo})
+			}
+		}
+	}
+
+	// Worktree directories which no repository knows about anymore, e.g. because git metadata was
+	// pruned or the repository was removed.
+	worktreeDirs, err := filepath.Glob(filepath.Join(im.configDir, "worktrees", "*"))
+	if err != nil {
+		return nil, err
+	}
+	projectWorktreeDirs, err := filepath.Glob(filepath.Join(im.configDir, ProjectsDirName, "*", ProjectWorktreesDirName, "*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range append(worktreeDirs, projectWorktreeDirs...) {
+		path := canonicalPath(dir)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if registered[path] || owned.worktrees[path] || isRecent(path) {
+			continue
+		}
+		worktrees = append(worktrees, Orphan{Kind: OrphanWorktree, Name: dir, Size: diskUsage(path)})
+	}
+
+	var sessions []Orphan
+	tmuxSessions, err := tmux.ListSessions(cmd.MakeExecutor())
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range tmuxSessions {
+		if owned.sessions[s.Name] || time.Since(s.Created) < orphanGracePeriod {
+			continue
+		}
+		sessions = append(sessions, Orphan{Kind: OrphanTmuxSession, Name: s.Name})
+	}
+
+	// Worktrees have to go before their branches can be deleted.
+	orphans := append(worktrees, branches...)
+	return append(orphans, sessions...), nil
+}
+
+// RemoveOrphan removes a resource returned by FindOrphans
+func RemoveOrphan(orphan Orphan) error {
+	switch orphan.Kind {
+	case OrphanWorktree:
+		if orphan.RepoPath != "" {
+			if err := git.RemoveWorktree(orphan.RepoPath, orphan.Name); err != nil {
+				log.WarningLog.Printf("gc: %v, removing the directory instead", err)
+			}
+		}
+		if err := os.RemoveAll(orphan.Name); err != nil {
+			return fmt.Errorf("failed to remove worktree directory %s: %w", orphan.Name, err)
+		}
+		if orphan.RepoPath != "" {
+			return git.PruneWorktrees(orphan.RepoPath)
+		}
+		return nil
+	case OrphanBranch:
+		return git.DeleteBranch(orphan.RepoPath, orphan.Name)
+	case OrphanTmuxSession:
+		return tmux.KillSession(cmd.MakeExecutor(), orphan.Name)
+	default:
+		return fmt.Errorf("unknown orphan kind %q", orphan.Kind)
+	}
+}
+
+// canonicalPath resolves symlinks so that paths from git and from storage can be compared. Paths
+// which don't exist are only cleaned.
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// isRecent reports whether path was modified within the orphan grace period
+func isRecent(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) < orphanGracePeriod
+}
+
+// diskUsage returns the total size of the files under path in bytes
+func diskUsage(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -37,15 +37,56 @@ func (g *GitWorktree) Diff() *DiffStats {
 		stats.Error = err
 		return stats
 	}
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
-			stats.Added++
-		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
-			stats.Removed++
-		}
-	}
-	stats.Content = content
+	return newDiffStats(content)
+}
 
+// newDiffStats returns the statistics of the diff content. The totals are the sums of the files, so
+// that they agree with Files.
+func newDiffStats(content string) *DiffStats {
+	stats := &DiffStats{Content: content}
+	for _, file := range stats.Files() {
+		stats.Added += file.Added
+		stats.Removed += file.Removed
+	}
 	return stats
 }
+
+// FileDiffStats holds the statistics of a single file in a diff
+type FileDiffStats struct {
+	// Path is the path of the file relative to the repository root. For deleted files, it's the old path.
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// Files parses the diff content and returns the statistics of every file in it, in diff order.
+func (d *DiffStats) Files() []FileDiffStats {
+	var files []FileDiffStats
+	var current *FileDiffStats
+	inHunk := false
+	for _, line := range strings.Split(d.Content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, FileDiffStats{})
+			current = &files[len(files)-1]
+			inHunk = false
+			// Fallback for diffs without ---/+++ lines (e.g. binary files or mode changes).
+			if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+				current.Path = line[idx+len(" b/"):]
+			}
+		case current == nil:
+			continue
+		case !inHunk && strings.HasPrefix(line, "--- a/"):
+			current.Path = strings.TrimPrefix(line, "--- a/")
+		case !inHunk && strings.HasPrefix(line, "+++ b/"):
+			current.Path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			current.Added++
+		case inHunk && strings.HasPrefix(line, "-"):
+			current.Removed++
+		}
+	}
+	return files
+}
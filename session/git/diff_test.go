@@ -0,0 +1,54 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStatsFiles(t *testing.T) {
+	content := `diff --git a/main.go b/main.go
+index 3f1a2b4..9c8d7e6 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,5 @@
+ package main
+-import "fmt"
++import (
++	"fmt"
++)
+diff --git a/new file.txt b/new file.txt
+new file mode 100644
+index 0000000..e69de29
+--- /dev/null
++++ b/new file.txt
+@@ -0,0 +1,2 @@
++++ starts with plus signs
++second line
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index e69de29..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+--- starts with minus signs
+diff --git a/image.png b/image.png
+index 1111111..2222222 100644
+Binary files a/image.png and b/image.png differ
+`
+	stats := &DiffStats{Content: content}
+	require.Equal(t, []FileDiffStats{
+		{Path: "main.go", Added: 3, Removed: 1},
+		{Path: "new file.txt", Added: 2, Removed: 0},
+		{Path: "old.txt", Added: 0, Removed: 1},
+		{Path: "image.png", Added: 0, Removed: 0},
+	}, stats.Files())
+
+	// Lines starting with +++ or --- inside hunks are counted in the totals as well.
+	stats = newDiffStats(content)
+	require.Equal(t, 5, stats.Added)
+	require.Equal(t, 2, stats.Removed)
+
+	require.Empty(t, (&DiffStats{}).Files())
+	require.True(t, newDiffStats("").IsEmpty())
+}
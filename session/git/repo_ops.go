@@ -0,0 +1,160 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// WorktreeEntry is a worktree as reported by `git worktree list --porcelain`
+type WorktreeEntry struct {
+	// Path is the absolute path of the worktree
+	Path string
+	// Branch is the checked out branch without the refs/heads/ prefix. Empty for detached worktrees.
+	Branch string
+	// Prunable is set if git considers the worktree stale, e.g. because its directory is gone
+	Prunable bool
+}
+
+// runRepoCommand runs a git command in the repository at repoPath
+func runRepoCommand(repoPath string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git command failed: %s (%w)", output, err)
+	}
+	return string(output), nil
+}
+
+// parseWorktreeList parses the output of `git worktree list --porcelain`
+func parseWorktreeList(output string) []WorktreeEntry {
+	var entries []WorktreeEntry
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			entries = append(entries, WorktreeEntry{Path: strings.TrimPrefix(line, "worktree ")})
+		case len(entries) == 0:
+			continue
+		case strings.HasPrefix(line, "branch "):
+			entries[len(entries)-1].Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		case line == "prunable" || strings.HasPrefix(line, "prunable "):
+			entries[len(entries)-1].Prunable = true
+		}
+	}
+	return entries
+}
+
+// ListWorktrees returns all worktrees of the repository at repoPath, including the main worktree
+func ListWorktrees(repoPath string) ([]WorktreeEntry, error) {
+	output, err := runRepoCommand(repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	return parseWorktreeList(output), nil
+}
+
+// ListBranches returns the local branches of the repository at repoPath whose name starts with prefix
+func ListBranches(repoPath string, prefix string) ([]string, error) {
+	output, err := runRepoCommand(repoPath, "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	var branches []string
+	for _, branch := range strings.Split(output, "\n") {
+		branch = strings.TrimSpace(branch)
+		if branch != "" && strings.HasPrefix(branch, prefix) {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// RemoveWorktree force-removes the worktree at worktreePath from the repository at repoPath
+func RemoveWorktree(repoPath string, worktreePath string) error {
+	if _, err := runRepoCommand(repoPath, "worktree", "remove", "-f", worktreePath); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", worktreePath, err)
+	}
+	return nil
+}
+
+// DeleteBranch force-deletes a local branch of the repository at repoPath
+func DeleteBranch(repoPath string, branch string) error {
+	if _, err := runRepoCommand(repoPath, "branch", "-D", branch); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// PruneWorktrees removes the administrative files of stale worktrees of the repository at repoPath
+func PruneWorktrees(repoPath string) error {
+	if _, err := runRepoCommand(repoPath, "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w", err)
+	}
+	return nil
+}
+
+// RepairWorktrees updates the administrative files of the repository at repoPath and of the given
+// worktrees after the repository or the worktrees were moved
+func RepairWorktrees(repoPath string, worktreePaths ...string) error {
+	args := append([]string{"worktree", "repair"}, worktreePaths...)
+	if _, err := runRepoCommand(repoPath, args...); err != nil {
+		return fmt.Errorf("failed to repair worktrees: %w", err)
+	}
+	return nil
+}
+
+// CommitExists reports whether the commit sha is present in the repository at repoPath
+func CommitExists(repoPath string, sha string) bool {
+	_, err := runRepoCommand(repoPath, "cat-file", "-e", sha+"^{commit}")
+	return err == nil
+}
+
+// BranchExists reports whether the repository at repoPath has a local branch with the given name
+func BranchExists(repoPath string, branch string) bool {
+	_, err := runRepoCommand(repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
+// CountCommits returns the number of commits on branch which are not reachable from baseCommitSHA
+func CountCommits(repoPath string, baseCommitSHA string, branch string) (int, error) {
+	output, err := runRepoCommand(repoPath, "rev-list", "--count", baseCommitSHA+".."+"refs/heads/"+branch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse commit count %q: %w", output, err)
+	}
+	return count, nil
+}
+
+// CreateBranch creates a local branch pointing at commit in the repository at repoPath
+func CreateBranch(repoPath string, branch string, commit string) error {
+	if _, err := runRepoCommand(repoPath, "branch", branch, commit); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// CreateBundle writes the commits of branch which are not reachable from baseCommitSHA to a git
+// bundle at bundlePath. The bundle can only be unbundled in a repository containing the base commit.
+func CreateBundle(repoPath string, bundlePath string, baseCommitSHA string, branch string) error {
+	if _, err := runRepoCommand(repoPath, "bundle", "create", bundlePath, "refs/heads/"+branch, "^"+baseCommitSHA); err != nil {
+		return fmt.Errorf("failed to create bundle of branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// FetchBundle creates the local branch targetBranch from the branch sourceBranch of the git bundle
+// at bundlePath. The bundle's prerequisite commits have to be present in the repository.
+func FetchBundle(repoPath string, bundlePath string, sourceBranch string, targetBranch string) error {
+	if _, err := runRepoCommand(repoPath, "bundle", "verify", bundlePath); err != nil {
+		return fmt.Errorf("failed to verify bundle: %w", err)
+	}
+	refspec := "refs/heads/" + sourceBranch + ":refs/heads/" + targetBranch
+	if _, err := runRepoCommand(repoPath, "fetch", bundlePath, refspec); err != nil {
+		return fmt.Errorf("failed to fetch branch %s from bundle: %w", sourceBranch, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	output := `worktree /home/user/repo
+HEAD 3f1a2b4c5d6e7f8091a2b3c4d5e6f708192a3b4c
+branch refs/heads/main
+
+worktree /home/user/.claude-squad/projects/abc/worktrees/foo_17a
+HEAD 9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d
+branch refs/heads/user/foo
+
+worktree /home/user/.claude-squad/worktrees/bar_17b
+HEAD 9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d
+branch refs/heads/user/bar
+prunable gitdir file points to non-existent location
+
+worktree /tmp/detached
+HEAD 9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d
+detached
+
+`
+	require.Equal(t, []WorktreeEntry{
+		{Path: "/home/user/repo", Branch: "main"},
+		{Path: "/home/user/.claude-squad/projects/abc/worktrees/foo_17a", Branch: "user/foo"},
+		{Path: "/home/user/.claude-squad/worktrees/bar_17b", Branch: "user/bar", Prunable: true},
+		{Path: "/tmp/detached"},
+	}, parseWorktreeList(output))
+
+	require.Empty(t, parseWorktreeList(""))
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	git := func(dir string, args ...string) string {
+		output, err := runRepoCommand(dir, args...)
+		require.NoError(t, err)
+		return strings.TrimSpace(output)
+	}
+
+	source := t.TempDir()
+	git(source, "init", "-q", "-b", "main")
+	git(source, "commit", "-q", "--allow-empty", "-m", "base")
+	base := git(source, "rev-parse", "HEAD")
+
+	target := t.TempDir()
+	git(target, "clone", "-q", source, ".")
+
+	git(source, "checkout", "-q", "-b", "user/feature")
+	git(source, "commit", "-q", "--allow-empty", "-m", "feature")
+	head := git(source, "rev-parse", "HEAD")
+
+	count, err := CountCommits(source, base, "user/feature")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	bundle := filepath.Join(t.TempDir(), "branch.bundle")
+	require.NoError(t, CreateBundle(source, bundle, base, "user/feature"))
+
+	require.True(t, CommitExists(target, base))
+	require.False(t, CommitExists(target, head))
+	require.False(t, BranchExists(target, "other/feature"))
+	require.NoError(t, FetchBundle(target, bundle, "user/feature", "other/feature"))
+	require.True(t, BranchExists(target, "other/feature"))
+	require.Equal(t, head, git(target, "rev-parse", "refs/heads/other/feature"))
+}
@@ -35,8 +35,8 @@ func sanitizeBranchName(s string) string {
 	return s
 }
 
-// checkGHCLI checks if GitHub CLI is installed and configured
-func checkGHCLI() error {
+// CheckGHCLI checks if GitHub CLI is installed and configured
+func CheckGHCLI() error {
 	// Check if gh is installed
 	if _, err := exec.LookPath("gh"); err != nil {
 		return fmt.Errorf("GitHub CLI (gh) is not installed. Please install it first")
@@ -22,7 +22,7 @@ func (g *GitWorktree) runGitCommand(path string, args ...string) (string, error)
 
 // PushChanges commits and pushes changes in the worktree to the remote branch
 func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
-	if err := checkGHCLI(); err != nil {
+	if err := CheckGHCLI(); err != nil {
 		return err
 	}
 
@@ -133,7 +133,7 @@ func (g *GitWorktree) GetCurrentBranch() (string, error) {
 // OpenBranchURL opens the branch URL in the default browser
 func (g *GitWorktree) OpenBranchURL() error {
 	// Check if GitHub CLI is available
-	if err := checkGHCLI(); err != nil {
+	if err := CheckGHCLI(); err != nil {
 		return err
 	}
 
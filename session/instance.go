@@ -1,6 +1,7 @@
 package session
 
 import (
+	"claude-squad/config"
 	"claude-squad/log"
 	"claude-squad/session/git"
 	"claude-squad/session/tmux"
@@ -31,6 +32,36 @@ const (
 	Error
 )
 
+// String returns the lower-case name of the status, as shown by the CLI.
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Ready:
+		return "ready"
+	case Loading:
+		return "loading"
+	case Translating:
+		return "translating"
+	case Paused:
+		return "paused"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("status(%d)", int(s))
+	}
+}
+
+// ParseStatus returns the status with the given name as returned by Status.String.
+func ParseStatus(name string) (Status, error) {
+	for s := Running; s <= Error; s++ {
+		if s.String() == strings.ToLower(name) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown status: %s", name)
+}
+
 // Instance is a running instance of claude code.
 type Instance struct {
 	// Title is the internal identifier of the instance (ASCII-safe).
@@ -55,6 +86,15 @@ type Instance struct {
 	UpdatedAt time.Time
 	// AutoYes is true if the instance should automatically press enter when prompted.
 	AutoYes bool
+	// NeedsApproval is true if the instance shows a prompt which the approval policy does not let
+	// AutoYes mode accept, so that it waits for a human.
+	NeedsApproval bool
+	// ErrorReason says why the instance is in the Error status, e.g. because its session died.
+	ErrorReason string
+	// AutoPaused is true if the instance was paused for being idle rather than by a human.
+	AutoPaused bool
+	// ScheduledBy is the name of the schedule which created the instance, empty if a human did.
+	ScheduledBy string
 	// Prompt is the initial prompt to pass to the instance on startup
 	Prompt string
 	// ProjectID is the ID of the project this instance belongs to
@@ -62,6 +102,9 @@ type Instance struct {
 
 	// DiffStats stores the current git diff statistics
 	diffStats *git.DiffStats
+	// readySince is when UpdateStatus first saw the instance Ready without a prompt, zero if it
+	// isn't.
+	readySince time.Time
 
 	// The below fields are initialized upon calling Start().
 
@@ -86,6 +129,10 @@ func (i *Instance) ToInstanceData() InstanceData {
 		UpdatedAt:   time.Now(),
 		Program:     i.Program,
 		AutoYes:     i.AutoYes,
+		ProjectID:   i.ProjectID,
+		ErrorReason: i.ErrorReason,
+		AutoPaused:  i.AutoPaused,
+		ScheduledBy: i.ScheduledBy,
 	}
 
 	// Only include worktree data if gitWorktree is initialized
@@ -113,6 +160,32 @@ func (i *Instance) ToInstanceData() InstanceData {
 
 // FromInstanceData creates a new Instance from serialized data
 func FromInstanceData(data InstanceData) (*Instance, error) {
+	instance := newInstanceFromData(data)
+
+	if instance.Paused() {
+		instance.started = true
+		instance.tmuxSession = tmux.NewTmuxSession(instance.Title, instance.Program)
+	} else {
+		if err := instance.Start(false); err != nil {
+			return nil, err
+		}
+	}
+
+	return instance, nil
+}
+
+// FromInstanceDataDetached creates an Instance from serialized data without attaching a PTY to its
+// tmux session. Keys are delivered through the tmux server instead, so the instance can be driven
+// while another process (e.g. the UI) owns the session's PTY.
+func FromInstanceDataDetached(data InstanceData) *Instance {
+	instance := newInstanceFromData(data)
+	instance.started = true
+	instance.tmuxSession = tmux.NewTmuxSession(instance.Title, instance.Program)
+	return instance
+}
+
+// newInstanceFromData creates an Instance from serialized data without touching its tmux session.
+func newInstanceFromData(data InstanceData) *Instance {
 	instance := &Instance{
 		Title:       data.Title,
 		DisplayName: data.DisplayName,
@@ -124,6 +197,11 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 		CreatedAt:   data.CreatedAt,
 		UpdatedAt:   data.UpdatedAt,
 		Program:     data.Program,
+		AutoYes:     data.AutoYes,
+		ProjectID:   data.ProjectID,
+		ErrorReason: data.ErrorReason,
+		AutoPaused:  data.AutoPaused,
+		ScheduledBy: data.ScheduledBy,
 		gitWorktree: git.NewGitWorktreeFromStorage(
 			data.Worktree.RepoPath,
 			data.Worktree.WorktreePath,
@@ -138,8 +216,8 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 		},
 	}
 
-	// Try to extract project ID from worktree path if available
-	if data.Worktree.WorktreePath != "" {
+	// Try to extract project ID from worktree path if it wasn't stored
+	if instance.ProjectID == "" && data.Worktree.WorktreePath != "" {
 		// Extract project ID from worktree path: .../projects/{project_id}/worktrees/...
 		parts := filepath.SplitList(data.Worktree.WorktreePath)
 		for i, part := range parts {
@@ -155,30 +233,25 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 		instance.DisplayName = instance.Title
 	}
 
-	if instance.Paused() {
-		instance.started = true
-		instance.tmuxSession = tmux.NewTmuxSession(instance.Title, instance.Program)
-	} else {
-		if err := instance.Start(false); err != nil {
-			return nil, err
-		}
-	}
-
-	return instance, nil
+	return instance
 }
 
 // Options for creating a new instance
 type InstanceOptions struct {
 	// Title is the title of the instance.
 	Title string
+	// DisplayName is the user-facing name of the instance. Defaults to Title if empty.
+	DisplayName string
 	// Path is the path to the workspace.
 	Path string
 	// Program is the program to run in the instance (e.g. "claude", "aider --model ollama_chat/gemma3:1b")
 	Program string
 	// ProjectID is the ID of the project this instance belongs to
 	ProjectID string
-	// If AutoYes is true, then
+	// If AutoYes is true, then the instance automatically accepts prompts.
 	AutoYes bool
+	// ScheduledBy is the name of the schedule creating the instance, if any.
+	ScheduledBy string
 }
 
 func NewInstance(opts InstanceOptions) (*Instance, error) {
@@ -190,9 +263,15 @@ func NewInstance(opts InstanceOptions) (*Instance, error) {
 		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
+	displayName := opts.DisplayName
+	if displayName == "" {
+		// Initially, DisplayName equals Title
+		displayName = opts.Title
+	}
+
 	return &Instance{
 		Title:       opts.Title,
-		DisplayName: opts.Title, // Initially, DisplayName equals Title
+		DisplayName: displayName,
 		Status:      Ready,
 		Path:        absPath,
 		Program:     opts.Program,
@@ -201,7 +280,8 @@ func NewInstance(opts InstanceOptions) (*Instance, error) {
 		Width:       0,
 		CreatedAt:   t,
 		UpdatedAt:   t,
-		AutoYes:     false,
+		AutoYes:     opts.AutoYes,
+		ScheduledBy: opts.ScheduledBy,
 	}, nil
 }
 
@@ -212,8 +292,27 @@ func (i *Instance) RepoName() (string, error) {
 	return i.gitWorktree.GetRepoName(), nil
 }
 
+// SetStatus sets the status of the instance. Leaving the Error status clears the error reason.
 func (i *Instance) SetStatus(status Status) {
 	i.Status = status
+	if status != Error {
+		i.ErrorReason = ""
+	}
+}
+
+// SetError puts the instance in the Error status and records why.
+func (i *Instance) SetError(reason string) {
+	i.Status = Error
+	i.ErrorReason = reason
+}
+
+// ApplyStatus takes over the status another process, e.g. the daemon, reported for the instance.
+func (i *Instance) ApplyStatus(data InstanceData) {
+	i.SetStatus(data.Status)
+	i.ErrorReason = data.ErrorReason
+	i.AutoPaused = data.AutoPaused
+	i.AutoYes = data.AutoYes
+	i.NeedsApproval = data.NeedsApproval
 }
 
 // firstTimeSetup is true if this is a new instance. Otherwise, it's one loaded from storage.
@@ -384,14 +483,91 @@ func (i *Instance) HasUpdated() (updated bool, hasPrompt bool) {
 	return i.tmuxSession.HasUpdated()
 }
 
-// TapEnter sends an enter key press to the tmux session if AutoYes is enabled.
-func (i *Instance) TapEnter() {
+// UpdateStatus updates the status from the pane content: the instance is Running while its output
+// changes and Ready once it stops changing, unless the program shows a prompt. NeedsApproval is set
+// if the instance has AutoYes enabled and shows a prompt the approval policy denies. It is meant to
+// be called periodically and returns whether a prompt is shown. Instances in the Error status are
+// left alone, the Supervisor takes care of them.
+func (i *Instance) UpdateStatus(approvals *config.ApprovalMatcher) (hasPrompt bool) {
+	if !i.started || i.Paused() || i.Status == Error {
+		return false
+	}
+	updated, hasPrompt := i.HasUpdated()
+	if updated {
+		i.SetStatus(Running)
+	} else if !hasPrompt {
+		i.SetStatus(Ready)
+	}
+	if i.Status != Ready || hasPrompt {
+		i.readySince = time.Time{}
+	} else if i.readySince.IsZero() {
+		i.readySince = time.Now()
+	}
+
+	neededApproval := i.NeedsApproval
+	i.NeedsApproval = false
+	if hasPrompt && i.AutoYes {
+		if allowed, reason := approvals.Allows(i.Program, i.tmuxSession.Prompt()); !allowed {
+			if !neededApproval {
+				log.InfoLog.Printf("leaving prompt of instance %s for a human: %s", i.Title, reason)
+			}
+			i.NeedsApproval = true
+		}
+	}
+	return hasPrompt && !updated
+}
+
+// RefreshStatus is UpdateStatus, but also taps enter on a prompt when AutoYes is enabled and the
+// approval policy allows it. Every accepted prompt is recorded in the audit log.
+func (i *Instance) RefreshStatus(approvals *config.ApprovalMatcher) (hasPrompt bool) {
+	hasPrompt = i.UpdateStatus(approvals)
+	if hasPrompt && !i.NeedsApproval {
+		// Captured before tapping, the pane may change right after.
+		prompt := i.tmuxSession.Prompt()
+		if i.TapEnter() {
+			recordApproval(i, prompt)
+		}
+	}
+	return hasPrompt
+}
+
+// IdleFor returns how long UpdateStatus has seen the instance Ready without a prompt.
+func (i *Instance) IdleFor() time.Duration {
+	if i.readySince.IsZero() {
+		return 0
+	}
+	return time.Since(i.readySince)
+}
+
+// PauseIfIdle pauses the instance like ProjectInstanceManager.PauseIfIdle, without storing it.
+func (i *Instance) PauseIfIdle(after time.Duration) (bool, error) {
+	if after <= 0 || i.Paused() || i.IdleFor() < after {
+		return false, nil
+	}
+	i.readySince = time.Now()
+	if dirty, err := i.gitWorktree.IsDirty(); err != nil {
+		return false, fmt.Errorf("failed to check if worktree is dirty: %w", err)
+	} else if dirty {
+		return false, nil
+	}
+	if err := i.Pause(); err != nil {
+		return false, err
+	}
+	i.AutoPaused = true
+	return true, nil
+}
+
+// TapEnter sends an enter key press to the tmux session if AutoYes is enabled. It returns whether
+// the key press was sent.
+func (i *Instance) TapEnter() bool {
 	if !i.started || !i.AutoYes {
-		return
+		return false
 	}
 	if err := i.tmuxSession.TapEnter(); err != nil {
 		log.ErrorLog.Printf("error tapping enter: %v", err)
+		return false
 	}
+	return true
 }
 
 func (i *Instance) Attach() (chan struct{}, error) {
@@ -512,6 +688,7 @@ func (i *Instance) Pause() error {
 
 	// Only set to Paused if all operations succeeded
 	i.SetStatus(Paused)
+	i.AutoPaused = false
 	_ = clipboard.WriteAll(i.gitWorktree.GetBranchName())
 	return nil
 }
@@ -523,7 +700,7 @@ func (i *Instance) Resume() error {
 	}
 
 	// Store original status for error recovery
-	originalStatus := i.Status
+	originalStatus, originalReason := i.Status, i.ErrorReason
 
 	// Set loading status to show spinner during resume operation
 	i.SetStatus(Translating)
@@ -534,8 +711,12 @@ func (i *Instance) Resume() error {
 	if err != nil {
 		// Restore original status on error
 		i.SetStatus(originalStatus)
+		if originalStatus == Error {
+			i.SetError(originalReason)
+		}
 		return err
 	}
+	i.AutoPaused = false
 
 	return nil
 }
@@ -624,6 +805,15 @@ func (i *Instance) doResume() error {
 	return nil
 }
 
+// ReleasePTY closes the PTY of a started instance, leaving its tmux session running. Processes
+// which don't attach to instances, like the daemon, use it to avoid holding a PTY per instance.
+func (i *Instance) ReleasePTY() error {
+	if !i.started || i.tmuxSession == nil {
+		return nil
+	}
+	return i.tmuxSession.ReleasePTY()
+}
+
 // RestartTmux attempts to restart the tmux session without recreating the worktree
 func (i *Instance) RestartTmux() error {
 	if !i.started {
@@ -714,6 +904,14 @@ func (i *Instance) PreviewFullHistory() (string, error) {
 	return i.tmuxSession.CapturePaneContentWithOptions("-", "-")
 }
 
+// FollowHistory returns a follower which reports the output of the instance, starting with its history
+func (i *Instance) FollowHistory() (*tmux.HistoryFollower, error) {
+	if !i.started || i.Status == Paused {
+		return nil, fmt.Errorf("instance '%s' is not running", i.Title)
+	}
+	return i.tmuxSession.NewHistoryFollower(), nil
+}
+
 // SetTmuxSession sets the tmux session for testing purposes
 func (i *Instance) SetTmuxSession(session *tmux.TmuxSession) {
 	i.tmuxSession = session
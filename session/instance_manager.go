@@ -3,6 +3,7 @@ package session
 import (
 	"claude-squad/config"
 	"claude-squad/log"
+	"claude-squad/session/tmux"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -32,11 +33,25 @@ func NewProjectInstanceManager(projectID, repoPath string, configDir string) *Pr
 
 // CreateInstance creates a new instance within the project
 func (pm *ProjectInstanceManager) CreateInstance(opts InstanceOptions) (*Instance, error) {
+	instance, err := pm.StartInstance(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := pm.StoreInstance(instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// StartInstance creates and starts a new instance within the project without storing it, which
+// StoreInstance does. Starting takes a while, so callers serializing access to the storage can
+// store the instance separately.
+func (pm *ProjectInstanceManager) StartInstance(opts InstanceOptions) (*Instance, error) {
 	// Set the project ID
 	opts.ProjectID = pm.projectID
 
-	// Check instance limit
-	instances, err := pm.GetAllInstances()
+	// Check instance limit. Only the stored data is needed to count the instances.
+	instances, err := pm.GetAllInstancesData()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load instances: %w", err)
 	}
@@ -44,6 +59,10 @@ func (pm *ProjectInstanceManager) CreateInstance(opts InstanceOptions) (*Instanc
 	if len(instances) >= ProjectInstanceLimit {
 		return nil, fmt.Errorf("project instance limit reached: maximum %d instances allowed", ProjectInstanceLimit)
 	}
+	// Reject duplicates before the worktree and the tmux session are created.
+	if err := checkTitleAvailable(instances, opts.Title); err != nil {
+		return nil, err
+	}
 
 	// Create new instance
 	instance, err := NewInstance(opts)
@@ -55,21 +74,76 @@ func (pm *ProjectInstanceManager) CreateInstance(opts InstanceOptions) (*Instanc
 	if err := instance.Start(true); err != nil {
 		return nil, fmt.Errorf("failed to start instance: %w", err)
 	}
+	return instance, nil
+}
 
+// StoreInstance adds an instance started by StartInstance to the project storage. The instance is
+// killed if it cannot be stored.
+func (pm *ProjectInstanceManager) StoreInstance(instance *Instance) error {
 	// Save instance to project storage
 	instanceData := instance.ToInstanceData()
 	if err := pm.projectStorage.AddInstance(instanceData); err != nil {
 		// Clean up the instance if saving fails
 		instance.Kill()
-		return nil, fmt.Errorf("failed to save instance: %w", err)
+		return fmt.Errorf("failed to save instance: %w", err)
 	}
 
 	// Update global state
-	if err := pm.globalManager.UpdateProjectInstanceCount(pm.projectID, len(instances)+1); err != nil {
-		log.WarningLog.Printf("Failed to update project instance count: %v", err)
+	if instances, err := pm.GetAllInstancesData(); err == nil {
+		if err := pm.globalManager.UpdateProjectInstanceCount(pm.projectID, len(instances)); err != nil {
+			log.WarningLog.Printf("Failed to update project instance count: %v", err)
+		}
 	}
+	return nil
+}
 
-	return instance, nil
+// MaxTitleLength is the longest title an instance may have.
+const MaxTitleLength = 32
+
+// ValidateTitle returns an error if the title cannot be used for an instance. The title names the
+// tmux session, the branch and files of the instance, so it is limited to letters, digits, spaces,
+// '-', '_' and '.', and has to start with a letter or digit.
+func ValidateTitle(title string) error {
+	if title == "" {
+		return fmt.Errorf("title cannot be empty")
+	}
+	if len(title) > MaxTitleLength {
+		return fmt.Errorf("title cannot be longer than %d characters", MaxTitleLength)
+	}
+	for i, r := range title {
+		alphanumeric := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if i == 0 && !alphanumeric {
+			return fmt.Errorf("title '%s' has to start with a letter or digit", title)
+		}
+		if !alphanumeric && r != ' ' && r != '-' && r != '_' && r != '.' {
+			return fmt.Errorf("title '%s' may only contain letters, digits, spaces, '-', '_' and '.'", title)
+		}
+	}
+	return nil
+}
+
+// CheckTitleAvailable returns an error if the title is used by an instance of the project or by a
+// tmux session.
+func (pm *ProjectInstanceManager) CheckTitleAvailable(title string) error {
+	instances, err := pm.GetAllInstancesData()
+	if err != nil {
+		return fmt.Errorf("failed to load instances: %w", err)
+	}
+	return checkTitleAvailable(instances, title)
+}
+
+// checkTitleAvailable returns an error if an instance with the title exists or its tmux session is
+// already taken, e.g. by an instance of another project.
+func checkTitleAvailable(instances []InstanceData, title string) error {
+	for _, existing := range instances {
+		if existing.Title == title {
+			return fmt.Errorf("instance with title '%s' already exists", title)
+		}
+	}
+	if tmux.NewTmuxSession(title, "").DoesSessionExist() {
+		return fmt.Errorf("a tmux session for '%s' already exists", title)
+	}
+	return nil
 }
 
 // GetAllInstances returns all instances for this project
@@ -100,6 +174,16 @@ func (pm *ProjectInstanceManager) GetAllInstances() ([]*Instance, error) {
 	return instances, nil
 }
 
+// GetAllInstancesData returns the stored data of all instances for this project without restoring
+// their tmux sessions.
+func (pm *ProjectInstanceManager) GetAllInstancesData() ([]InstanceData, error) {
+	instancesData, err := pm.projectStorage.GetInstances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load instances data: %w", err)
+	}
+	return instancesData, nil
+}
+
 // GetInstance returns a specific instance by title
 func (pm *ProjectInstanceManager) GetInstance(title string) (*Instance, error) {
 	instances, err := pm.GetAllInstances()
@@ -116,6 +200,37 @@ func (pm *ProjectInstanceManager) GetInstance(title string) (*Instance, error) {
 	return nil, fmt.Errorf("instance not found: %s", title)
 }
 
+// GetAllInstancesDetached returns all instances for this project without attaching to their tmux
+// sessions. See FromInstanceDataDetached.
+func (pm *ProjectInstanceManager) GetAllInstancesDetached() ([]*Instance, error) {
+	instancesData, err := pm.GetAllInstancesData()
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*Instance, 0, len(instancesData))
+	for _, data := range instancesData {
+		instances = append(instances, FromInstanceDataDetached(data))
+	}
+	return instances, nil
+}
+
+// GetInstanceDetached returns a specific instance by title without attaching to its tmux session.
+func (pm *ProjectInstanceManager) GetInstanceDetached(title string) (*Instance, error) {
+	instancesData, err := pm.GetAllInstancesData()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, data := range instancesData {
+		if data.Title == title {
+			return FromInstanceDataDetached(data), nil
+		}
+	}
+
+	return nil, fmt.Errorf("instance not found: %s", title)
+}
+
 // SaveInstance saves an instance to storage (creates if new, updates if existing)
 func (pm *ProjectInstanceManager) SaveInstance(instance *Instance) error {
 	if !instance.Started() {
@@ -160,10 +275,52 @@ func (pm *ProjectInstanceManager) UpdateInstance(instance *Instance) error {
 	return nil
 }
 
+// SaveDiffStats updates the stored diff stats of the given instances. The rest of their stored data
+// is left untouched, since another process may have changed it in the meantime, e.g. paused one of
+// the instances.
+func (pm *ProjectInstanceManager) SaveDiffStats(instances []*Instance) error {
+	instancesData, err := pm.GetAllInstancesData()
+	if err != nil {
+		return err
+	}
+	diffStats := make(map[string]DiffStatsData, len(instances))
+	for _, instance := range instances {
+		diffStats[instance.Title] = instance.ToInstanceData().DiffStats
+	}
+	for i := range instancesData {
+		if stats, ok := diffStats[instancesData[i].Title]; ok {
+			instancesData[i].DiffStats = stats
+		}
+	}
+	return pm.projectStorage.SaveInstances(instancesData)
+}
+
+// SaveStatus updates the stored status and error reason of an instance, leaving the rest of its
+// stored data untouched like SaveDiffStats does.
+func (pm *ProjectInstanceManager) SaveStatus(instance *Instance) error {
+	instancesData, err := pm.GetAllInstancesData()
+	if err != nil {
+		return err
+	}
+	for i := range instancesData {
+		if instancesData[i].Title == instance.Title {
+			if instancesData[i].Status == Paused && !instance.Paused() {
+				// Another process paused the instance in the meantime.
+				return nil
+			}
+			instancesData[i].Status = instance.Status
+			instancesData[i].ErrorReason = instance.ErrorReason
+			return pm.projectStorage.SaveInstances(instancesData)
+		}
+	}
+	return fmt.Errorf("instance not found: %s", instance.Title)
+}
+
 // DeleteInstance deletes an instance from the project
 func (pm *ProjectInstanceManager) DeleteInstance(title string) error {
-	// Get instance to clean up resources
-	instance, err := pm.GetInstance(title)
+	// Get instance to clean up resources. Killing only talks to the tmux server, so there is no need
+	// to attach to the session first.
+	instance, err := pm.GetInstanceDetached(title)
 	if err != nil {
 		return fmt.Errorf("failed to get instance: %w", err)
 	}
@@ -177,9 +334,12 @@ func (pm *ProjectInstanceManager) DeleteInstance(title string) error {
 	if err := pm.projectStorage.DeleteInstance(title); err != nil {
 		return fmt.Errorf("failed to delete instance from storage: %w", err)
 	}
+	if err := os.Remove(pm.importedHistoryPath(title)); err != nil && !os.IsNotExist(err) {
+		log.WarningLog.Printf("Failed to remove imported history: %v", err)
+	}
 
 	// Update global state
-	instances, err := pm.GetAllInstances()
+	instances, err := pm.GetAllInstancesData()
 	if err != nil {
 		log.WarningLog.Printf("Failed to get instance count for update: %v", err)
 	} else {
@@ -236,8 +396,14 @@ func (im *InstanceManager) GetCurrentProjectManager() (*ProjectInstanceManager,
 	}
 	log.InfoLog.Printf("[PROJECT] Current working directory: %s", cwd)
 
+	return im.GetProjectManagerForPath(cwd)
+}
+
+// GetProjectManagerForPath returns the project manager for the git repository containing path. The
+// project is registered if it is new.
+func (im *InstanceManager) GetProjectManagerForPath(path string) (*ProjectInstanceManager, error) {
 	// Find Git repository root
-	repoPath, err := findGitRepoRootFromPath(cwd)
+	repoPath, err := findGitRepoRootFromPath(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find Git repository root: %w", err)
 	}
@@ -271,12 +437,13 @@ func (im *InstanceManager) GetCurrentProjectManager() (*ProjectInstanceManager,
 	projectManager := im.GetProjectManager(projectID, repoPath)
 	log.InfoLog.Printf("[PROJECT] Created project manager for project %s", projectID)
 
-	// Test loading instances to verify project state
-	instances, err := projectManager.GetAllInstances()
+	// Test loading instances to verify project state. Only the stored data is read here so that
+	// callers which never touch tmux (e.g. the CLI) don't attach to every session.
+	instancesData, err := projectManager.GetAllInstancesData()
 	if err != nil {
 		log.InfoLog.Printf("[PROJECT] Warning: failed to load instances for project %s: %v", projectID, err)
 	} else {
-		log.InfoLog.Printf("[PROJECT] Successfully loaded %d instances for project %s", len(instances), projectID)
+		log.InfoLog.Printf("[PROJECT] Successfully loaded %d instances for project %s", len(instancesData), projectID)
 	}
 
 	return projectManager, nil
@@ -0,0 +1,23 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTitleAvailable(t *testing.T) {
+	instances := []InstanceData{{Title: "foo"}, {Title: "bar"}}
+	assert.ErrorContains(t, checkTitleAvailable(instances, "foo"), "already exists")
+	assert.NoError(t, checkTitleAvailable(instances, "claude-squad-test-unused-title"))
+}
+
+func TestValidateTitle(t *testing.T) {
+	for _, title := range []string{"foo", "fix login bug", "deps-bump-0304-0200", "v1.2_rc", strings.Repeat("a", MaxTitleLength)} {
+		assert.NoError(t, ValidateTitle(title), title)
+	}
+	for _, title := range []string{"", "../../x", "a/b", ".hidden", "-rf", " foo", "foo;rm", "tëst", strings.Repeat("a", MaxTitleLength+1)} {
+		assert.Error(t, ValidateTitle(title), title)
+	}
+}
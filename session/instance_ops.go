@@ -0,0 +1,97 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// PauseInstance pauses an instance and stores its new status. Pausing a paused instance does nothing.
+func (pm *ProjectInstanceManager) PauseInstance(instance *Instance) error {
+	if instance.Paused() {
+		return nil
+	}
+	if err := instance.Pause(); err != nil {
+		return err
+	}
+	return pm.UpdateInstance(instance)
+}
+
+// ResumeInstance resumes a paused instance and stores its new status. Resuming recreates the worktree,
+// so an instance which isn't paused is rejected rather than losing its uncommitted changes.
+func (pm *ProjectInstanceManager) ResumeInstance(instance *Instance) error {
+	if !instance.Paused() {
+		return fmt.Errorf("instance %s is not paused", instance.Title)
+	}
+	if err := instance.Resume(); err != nil {
+		return err
+	}
+	return pm.UpdateInstance(instance)
+}
+
+// PauseIfIdle pauses an instance which has been idle for at least the given duration, unless its
+// worktree has uncommitted changes, and stores it as AutoPaused. An instance which isn't paused, e.g.
+// because of uncommitted changes, is only checked again after it was idle for another such period. A
+// zero duration disables pausing. It returns whether the instance was paused.
+func (pm *ProjectInstanceManager) PauseIfIdle(instance *Instance, after time.Duration) (bool, error) {
+	paused, err := instance.PauseIfIdle(after)
+	if !paused || err != nil {
+		return false, err
+	}
+	return true, pm.UpdateInstance(instance)
+}
+
+// KillInstance kills an instance and removes its worktree and branch. It refuses to, if the branch
+// is checked out in the repository.
+func (pm *ProjectInstanceManager) KillInstance(instance *Instance) error {
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		return err
+	}
+	checkedOut, err := worktree.IsBranchCheckedOut()
+	if err != nil {
+		return err
+	}
+	if checkedOut {
+		return fmt.Errorf("branch %s is currently checked out", worktree.GetBranchName())
+	}
+	return pm.DeleteInstance(instance.Title)
+}
+
+// SendPrompt sends a prompt to a running instance. If autoResume is set, an instance which was paused
+// for being idle is resumed first and its new status stored. It returns whether the instance was
+// resumed.
+func (pm *ProjectInstanceManager) SendPrompt(instance *Instance, prompt string, autoResume bool) (bool, error) {
+	resumed := false
+	if autoResume && instance.Paused() && instance.AutoPaused {
+		if err := pm.ResumeInstance(instance); err != nil {
+			return false, fmt.Errorf("failed to resume instance: %w", err)
+		}
+		resumed = true
+	}
+	return resumed, SendPromptToInstance(instance, prompt)
+}
+
+// SendPromptToInstance sends a prompt to a running instance.
+func SendPromptToInstance(instance *Instance, prompt string) error {
+	if instance.Paused() {
+		return fmt.Errorf("instance '%s' is paused, resume it before sending a prompt", instance.Title)
+	}
+	if !instance.TmuxAlive() {
+		return fmt.Errorf("tmux session of instance '%s' is not running", instance.Title)
+	}
+	return instance.SendPrompt(prompt)
+}
+
+// SendInitialPrompt waits for the program of a newly created instance to stop producing output after
+// startup and then sends the prompt. Typing into the pane while the program is still booting can
+// drop keystrokes.
+func SendInitialPrompt(instance *Instance, prompt string) error {
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(500 * time.Millisecond)
+		if updated, _ := instance.HasUpdated(); !updated {
+			break
+		}
+	}
+	return instance.SendPrompt(prompt)
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"claude-squad/cmd/cmd_test"
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIdleInstance returns a started instance with a real worktree and a mocked tmux session, which
+// has been ready for an hour, stored in a new project.
+func newIdleInstance(t *testing.T) (*ProjectInstanceManager, *Instance) {
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	gitCmd := func(dir string, args ...string) {
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	repo := t.TempDir()
+	gitCmd(repo, "init", "-q", "-b", "main")
+	gitCmd(repo, "commit", "-q", "--allow-empty", "-m", "base")
+	worktreePath := filepath.Join(t.TempDir(), "agent")
+	gitCmd(repo, "worktree", "add", "-q", "-b", "test/agent", worktreePath)
+
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc:    func(cmd *exec.Cmd) error { return nil },
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) { return nil, nil },
+	}
+	instance := &Instance{
+		Title:       "agent",
+		Path:        repo,
+		Branch:      "test/agent",
+		Program:     "claude",
+		Status:      Ready,
+		CreatedAt:   time.Now(),
+		started:     true,
+		readySince:  time.Now().Add(-time.Hour),
+		tmuxSession: tmux.NewTmuxSessionWithDeps("agent", "claude", nil, cmdExec),
+		gitWorktree: git.NewGitWorktreeFromStorage(repo, worktreePath, "agent", "test/agent", ""),
+	}
+	pm := NewProjectInstanceManager("project", repo, t.TempDir())
+	require.NoError(t, pm.projectStorage.AddInstance(instance.ToInstanceData()))
+	return pm, instance
+}
+
+func TestPauseIfIdle(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	pm, instance := newIdleInstance(t)
+
+	paused, err := pm.PauseIfIdle(instance, 0)
+	require.NoError(t, err)
+	assert.False(t, paused, "a zero duration disables pausing")
+	paused, err = pm.PauseIfIdle(instance, 2*time.Hour)
+	require.NoError(t, err)
+	assert.False(t, paused, "the instance has not been idle for long enough")
+
+	paused, err = pm.PauseIfIdle(instance, 30*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, paused)
+	assert.Equal(t, Paused, instance.Status)
+	assert.True(t, instance.AutoPaused)
+	assert.NoDirExists(t, instance.gitWorktree.GetWorktreePath())
+
+	stored, err := pm.GetAllInstancesData()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, Paused, stored[0].Status)
+	assert.True(t, stored[0].AutoPaused)
+
+	// A paused instance is left alone.
+	paused, err = pm.PauseIfIdle(instance, 30*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
+
+func TestPauseIfIdleKeepsDirtyWorktree(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	pm, instance := newIdleInstance(t)
+	worktreePath := instance.gitWorktree.GetWorktreePath()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "notes.txt"), []byte("wip"), 0644))
+
+	paused, err := pm.PauseIfIdle(instance, 30*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, paused)
+	assert.Equal(t, Ready, instance.Status)
+	assert.False(t, instance.AutoPaused)
+	assert.FileExists(t, filepath.Join(worktreePath, "notes.txt"))
+
+	// The instance is only checked again after it was idle for another period.
+	assert.Less(t, instance.IdleFor(), time.Minute)
+	paused, err = pm.PauseIfIdle(instance, 30*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
+
+func TestResumeInstanceKeepsRunningWorktree(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	pm, instance := newIdleInstance(t)
+	worktreePath := instance.gitWorktree.GetWorktreePath()
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "uncommitted.txt"), []byte("wip"), 0644))
+
+	assert.ErrorContains(t, pm.ResumeInstance(instance), "is not paused")
+	assert.Equal(t, Ready, instance.Status)
+	assert.FileExists(t, filepath.Join(worktreePath, "uncommitted.txt"))
+}
+
+func TestSendPromptResumesOnlyIdlePausedInstances(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	pm, instance := newIdleInstance(t)
+	instance.SetStatus(Paused)
+
+	// The instance was paused by hand, so it is not resumed.
+	resumed, err := pm.SendPrompt(instance, "hello", true)
+	assert.False(t, resumed)
+	assert.ErrorContains(t, err, "is paused")
+
+	// Without auto-resume, idle instances stay paused as well.
+	instance.AutoPaused = true
+	resumed, err = pm.SendPrompt(instance, "hello", false)
+	assert.False(t, resumed)
+	assert.ErrorContains(t, err, "is paused")
+	assert.Equal(t, Paused, instance.Status)
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LeaseFileName is the name of the file in a project directory which is locked by the process
+// owning the project's instances.
+const LeaseFileName = "owner.lock"
+
+// errLeaseHeld is returned by lockFile if another process holds the lock.
+var errLeaseHeld = errors.New("lease is held by another process")
+
+// LeaseHolder describes the process holding the lease of a project.
+type LeaseHolder struct {
+	PID int `json:"pid"`
+	// Owner is the kind of process, e.g. "daemon" or "ui".
+	Owner string    `json:"owner"`
+	Since time.Time `json:"since"`
+}
+
+// Lease is the ownership of a project's instances. Exactly one process holds the lease of a project
+// at a time: it accepts prompts in AutoYes mode, restarts dead sessions, pauses idle instances and
+// stores their status. Other processes only observe the instances. The lease is a lock on a file,
+// so the OS releases it when the holder exits or crashes, and another process can take it over.
+type Lease struct {
+	path  string
+	owner string
+	// file is the locked lease file, nil if the lease is not held.
+	file *os.File
+}
+
+// NewLease returns the lease of the project for a process of the given kind, e.g. "daemon". It is
+// not held until TryAcquire succeeds.
+func (pm *ProjectInstanceManager) NewLease(owner string) *Lease {
+	return &Lease{path: filepath.Join(pm.GetProjectDir(), LeaseFileName), owner: owner}
+}
+
+// TryAcquire takes the lease unless another process holds it, without blocking. It returns whether
+// the lease is held by this process.
+func (l *Lease) TryAcquire() (bool, error) {
+	if l.file != nil {
+		return true, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create project directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open lease file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLeaseHeld) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to lock lease file: %w", err)
+	}
+
+	// The holder is recorded for humans, the lock alone decides who holds the lease.
+	holder, err := json.Marshal(LeaseHolder{PID: os.Getpid(), Owner: l.owner, Since: time.Now()})
+	if err == nil {
+		if err = f.Truncate(0); err == nil {
+			_, err = f.WriteAt(holder, 0)
+		}
+	}
+	if err != nil {
+		unlockFile(f)
+		f.Close()
+		return false, fmt.Errorf("failed to write lease file: %w", err)
+	}
+	l.file = f
+	return true, nil
+}
+
+// Held returns whether this process holds the lease.
+func (l *Lease) Held() bool {
+	return l.file != nil
+}
+
+// Release hands the lease back, so that another process can take it over. Releasing a lease which
+// is not held does nothing.
+func (l *Lease) Release() error {
+	if l.file == nil {
+		return nil
+	}
+	f := l.file
+	l.file = nil
+	// Clear the holder first, the lock is still ours until the file is unlocked.
+	_ = f.Truncate(0)
+	unlockFile(f)
+	return f.Close()
+}
+
+// LeaseHolder returns the process holding the lease of the project, or nil if nobody holds it.
+func (pm *ProjectInstanceManager) LeaseHolder() (*LeaseHolder, error) {
+	path := filepath.Join(pm.GetProjectDir(), LeaseFileName)
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open lease file: %w", err)
+	}
+	defer f.Close()
+
+	// The file is left behind by holders which crashed, only the lock tells whether it is held. This
+	// briefly takes the lock, a process trying to acquire the lease just then tries again later.
+	if err := lockFile(f); err == nil {
+		unlockFile(f)
+		return nil, nil
+	} else if !errors.Is(err, errLeaseHeld) {
+		return nil, fmt.Errorf("failed to check lease file: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lease file: %w", err)
+	}
+	var holder LeaseHolder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		// The holder has locked the file but not written it yet.
+		return &LeaseHolder{}, nil
+	}
+	return &holder, nil
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLease(t *testing.T) {
+	pm := NewProjectInstanceManager("project", t.TempDir(), t.TempDir())
+
+	holder, err := pm.LeaseHolder()
+	require.NoError(t, err)
+	assert.Nil(t, holder)
+
+	daemonLease := pm.NewLease("daemon")
+	held, err := daemonLease.TryAcquire()
+	require.NoError(t, err)
+	assert.True(t, held)
+	held, err = daemonLease.TryAcquire()
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// The lock is per open file, so a second lease in the same process behaves like another process.
+	uiLease := pm.NewLease("ui")
+	held, err = uiLease.TryAcquire()
+	require.NoError(t, err)
+	assert.False(t, held)
+	assert.False(t, uiLease.Held())
+
+	holder, err = pm.LeaseHolder()
+	require.NoError(t, err)
+	require.NotNil(t, holder)
+	assert.Equal(t, "daemon", holder.Owner)
+	assert.Equal(t, os.Getpid(), holder.PID)
+
+	require.NoError(t, daemonLease.Release())
+	assert.False(t, daemonLease.Held())
+	holder, err = pm.LeaseHolder()
+	require.NoError(t, err)
+	assert.Nil(t, holder)
+
+	held, err = uiLease.TryAcquire()
+	require.NoError(t, err)
+	assert.True(t, held)
+	require.NoError(t, uiLease.Release())
+	require.NoError(t, uiLease.Release())
+}
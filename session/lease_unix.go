@@ -0,0 +1,23 @@
+//go:build !windows
+
+package session
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile locks the file exclusively without blocking. It returns errLeaseHeld if another process
+// holds the lock.
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLeaseHeld
+	}
+	return err
+}
+
+func unlockFile(f *os.File) {
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
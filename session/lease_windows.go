@@ -0,0 +1,31 @@
+//go:build windows
+
+package session
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// leaseLockOffset is where the locked byte lies. It is past the holder written to the file, which
+// would otherwise be unreadable for other processes.
+const leaseLockOffset = 1 << 30
+
+// lockFile locks the file exclusively without blocking. It returns errLeaseHeld if another process
+// holds the lock.
+func lockFile(f *os.File) error {
+	overlapped := windows.Overlapped{Offset: leaseLockOffset}
+	err := windows.LockFileEx(windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLeaseHeld
+	}
+	return err
+}
+
+func unlockFile(f *os.File) {
+	overlapped := windows.Overlapped{Offset: leaseLockOffset}
+	_ = windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &overlapped)
+}
@@ -0,0 +1,204 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WorktreesDiskUsage returns the disk usage of the project's worktrees directory in bytes
+func (pm *ProjectInstanceManager) WorktreesDiskUsage() int64 {
+	return diskUsage(pm.projectStorage.GetProjectWorktreesDir())
+}
+
+// GetProjectDir returns the directory the project's state and worktrees are stored in
+func (pm *ProjectInstanceManager) GetProjectDir() string {
+	return pm.projectStorage.GetProjectDir()
+}
+
+// DeleteAllInstances kills all instances of the project including their worktrees, branches and
+// tmux sessions, and removes anything left in the project's worktrees directory. Instance resources
+// which cannot be removed, e.g. because the repository is gone, are logged and skipped.
+func (pm *ProjectInstanceManager) DeleteAllInstances() error {
+	instances, err := pm.GetAllInstancesData()
+	if err != nil {
+		return fmt.Errorf("failed to load instances: %w", err)
+	}
+	var errs []error
+	for _, data := range instances {
+		if err := pm.DeleteInstance(data.Title); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete instance %s: %w", data.Title, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// Worktrees git could not remove, and ones no instance owned anymore.
+	worktreesDir := pm.projectStorage.GetProjectWorktreesDir()
+	entries, err := os.ReadDir(worktreesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read worktrees directory: %w", err)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(worktreesDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove worktree %s: %w", entry.Name(), err)
+		}
+	}
+	if len(entries) > 0 && git.IsGitRepo(pm.repoPath) {
+		if err := git.PruneWorktrees(pm.repoPath); err != nil {
+			log.WarningLog.Printf("failed to prune worktrees of %s: %v", pm.repoPath, err)
+		}
+	}
+	return nil
+}
+
+// RemoveProject kills all instances of a project including their worktrees, branches and tmux
+// sessions, deletes the project directory and removes the project from the global state. Instance
+// resources which cannot be removed, e.g. because the repository is gone, are logged and skipped.
+func (im *InstanceManager) RemoveProject(projectID string) error {
+	project, err := im.globalManager.GetProject(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project not found: %s", projectID)
+	}
+
+	pm := im.GetProjectManager(project.ID, project.RepoPath)
+	if err := pm.DeleteAllInstances(); err != nil {
+		return fmt.Errorf("failed to delete instances of project %s: %w", project.Name, err)
+	}
+	if err := os.RemoveAll(pm.GetProjectDir()); err != nil {
+		return fmt.Errorf("failed to remove project directory: %w", err)
+	}
+
+	return im.globalManager.RemoveProject(projectID)
+}
+
+// RelinkProject moves a project to a repository at a new path, e.g. after the repository was
+// moved or cloned again. Since project IDs are derived from the repository path, the project gets
+// a new ID and its directory is moved accordingly. Worktrees are repaired to point at the new
+// repository.
+func (im *InstanceManager) RelinkProject(projectID string, newRepoPath string) (*config.GlobalProjectData, error) {
+	project, err := im.globalManager.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found: %s", projectID)
+	}
+	oldProject := *project
+
+	absPath, err := filepath.Abs(newRepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	repoPath, err := findGitRepoRootFromPath(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Git repository root: %w", err)
+	}
+	newID := config.GenerateProjectID(repoPath)
+	if newID == oldProject.ID {
+		return nil, fmt.Errorf("project %s is already linked to %s", oldProject.Name, repoPath)
+	}
+	if existing, err := im.globalManager.GetProject(newID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	} else if existing != nil {
+		return nil, fmt.Errorf("project %s already uses %s, remove it first", existing.Name, repoPath)
+	}
+
+	oldPM := im.GetProjectManager(oldProject.ID, oldProject.RepoPath)
+	newPM := im.GetProjectManager(newID, repoPath)
+	instances, err := oldPM.GetAllInstancesData()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load instances of project %s: %w", oldProject.Name, err)
+	}
+	if _, err := os.Stat(newPM.GetProjectDir()); err == nil {
+		return nil, fmt.Errorf("project directory %s already exists", newPM.GetProjectDir())
+	}
+	if _, err := os.Stat(oldPM.GetProjectDir()); err == nil {
+		if err := os.Rename(oldPM.GetProjectDir(), newPM.GetProjectDir()); err != nil {
+			return nil, fmt.Errorf("failed to move project directory: %w", err)
+		}
+	}
+
+	replacePrefix := func(path, oldPrefix, newPrefix string) string {
+		if path == oldPrefix || strings.HasPrefix(path, oldPrefix+string(filepath.Separator)) {
+			return newPrefix + strings.TrimPrefix(path, oldPrefix)
+		}
+		return path
+	}
+	var worktreePaths []string
+	for i := range instances {
+		data := &instances[i]
+		data.ProjectID = newID
+		data.Path = replacePrefix(data.Path, oldProject.RepoPath, repoPath)
+		data.Worktree.RepoPath = repoPath
+		data.Worktree.WorktreePath = replacePrefix(data.Worktree.WorktreePath, oldPM.GetProjectDir(), newPM.GetProjectDir())
+		if _, err := os.Stat(data.Worktree.WorktreePath); err == nil {
+			worktreePaths = append(worktreePaths, data.Worktree.WorktreePath)
+		}
+	}
+
+	state := &ProjectState{
+		Project: ProjectData{
+			ID:            newID,
+			Name:          filepath.Base(repoPath),
+			RepoPath:      repoPath,
+			CreatedAt:     oldProject.CreatedAt,
+			UpdatedAt:     time.Now(),
+			InstanceCount: len(instances),
+		},
+		Instances: instances,
+	}
+	if err := newPM.projectStorage.SaveProjectState(state); err != nil {
+		return nil, err
+	}
+	if err := im.globalManager.AddProject(newID, state.Project.Name, repoPath); err != nil {
+		return nil, fmt.Errorf("failed to add project: %w", err)
+	}
+	if err := im.globalManager.UpdateProjectInstanceCount(newID, len(instances)); err != nil {
+		log.WarningLog.Printf("Failed to update project instance count: %v", err)
+	}
+	if oldProject.RestartPolicy != nil {
+		if err := im.globalManager.SetProjectRestartPolicy(newID, oldProject.RestartPolicy); err != nil {
+			log.WarningLog.Printf("Failed to keep the restart policy of the project: %v", err)
+		}
+	}
+	if err := im.globalManager.RemoveProject(oldProject.ID); err != nil {
+		return nil, fmt.Errorf("failed to remove old project: %w", err)
+	}
+
+	if len(worktreePaths) > 0 {
+		if err := git.RepairWorktrees(repoPath, worktreePaths...); err != nil {
+			return nil, err
+		}
+	}
+	return im.globalManager.GetProject(newID)
+}
+
+// SetRestartPolicy sets the restart policy of a project. A nil policy disables restarts.
+func (im *InstanceManager) SetRestartPolicy(projectID string, policy *config.RestartPolicy) error {
+	return im.globalManager.SetProjectRestartPolicy(projectID, policy)
+}
+
+// RestartPolicy returns the restart policy of a project as currently stored, so that changes made
+// by other processes are seen. It returns nil if the project has none or can't be loaded.
+func (im *InstanceManager) RestartPolicy(projectID string) *config.RestartPolicy {
+	project, err := config.NewGlobalStateManager(im.configDir).GetProject(projectID)
+	if err != nil {
+		log.WarningLog.Printf("failed to load the restart policy of project %s: %v", projectID, err)
+		return nil
+	}
+	if project == nil {
+		return nil
+	}
+	return project.RestartPolicy
+}
@@ -15,6 +15,7 @@ const (
 	ProjectsDirName     = "projects"
 	ProjectStateFileName = "state.json"
 	ProjectWorktreesDirName = "worktrees"
+	ProjectHistoryDirName = "history"
 	ProjectInstanceLimit = 10
 )
 
@@ -77,6 +78,11 @@ func (ps *ProjectStorage) GetProjectWorktreesDir() string {
 	return filepath.Join(ps.GetProjectDir(), ProjectWorktreesDirName)
 }
 
+// GetProjectHistoryDir returns the directory holding the pane history of imported instances
+func (ps *ProjectStorage) GetProjectHistoryDir() string {
+	return filepath.Join(ps.GetProjectDir(), ProjectHistoryDirName)
+}
+
 // EnsureProjectDir creates the project directory structure if it doesn't exist
 func (ps *ProjectStorage) EnsureProjectDir() error {
 	projectDir := ps.GetProjectDir()
@@ -19,8 +19,18 @@ type InstanceData struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	AutoYes     bool      `json:"auto_yes"`
+	// NeedsApproval is set while the instance waits for a human to answer a prompt which AutoYes
+	// mode may not accept. Only the daemon reports it, it is not stored.
+	NeedsApproval bool `json:"needs_approval,omitempty"`
+	// ErrorReason says why the instance is in the Error status.
+	ErrorReason string `json:"error_reason,omitempty"`
+	// AutoPaused is set if the instance was paused for being idle.
+	AutoPaused bool `json:"auto_paused,omitempty"`
+	// ScheduledBy is the name of the schedule which created the instance, if any.
+	ScheduledBy string `json:"scheduled_by,omitempty"`
 
 	Program   string          `json:"program"`
+	ProjectID string          `json:"project_id,omitempty"`
 	Worktree  GitWorktreeData `json:"worktree"`
 	DiffStats DiffStatsData   `json:"diff_stats"`
 }
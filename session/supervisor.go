@@ -0,0 +1,147 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"fmt"
+	"time"
+)
+
+const (
+	// restartResetAfter is how long a restarted session has to stay alive before its restarts are
+	// forgotten. A program which keeps crashing right after the restart runs out of retries instead.
+	restartResetAfter = 10 * time.Minute
+	// policyRecheckInterval is how often the restart policy of a dead instance is looked up again
+	// while it doesn't allow a restart, so that changes to it take effect.
+	policyRecheckInterval = 10 * time.Second
+)
+
+// restartState tracks the restarts of a dead instance.
+type restartState struct {
+	// restarts is the number of restarts in a row.
+	restarts int
+	// next is the earliest time of the next restart or policy lookup.
+	next time.Time
+	// restartedAt is the time of the last restart.
+	restartedAt time.Time
+}
+
+// Supervisor notices instances whose tmux session died, e.g. because the program crashed, puts them
+// in the Error status and restarts them as the restart policy of their project allows, backing off
+// exponentially between restarts. Check is meant to be called periodically for every instance.
+type Supervisor struct {
+	// policy returns the restart policy of a project. If it is nil, dead sessions are only reported.
+	policy func(projectID string) *config.RestartPolicy
+	// detached is set for processes which don't keep a PTY per instance, like the daemon.
+	detached bool
+	now      func() time.Time
+	states   map[string]*restartState
+}
+
+// NewSupervisor returns a supervisor restarting dead sessions according to the given restart
+// policies. Processes which drive their instances without a PTY, like the daemon, set detached.
+func NewSupervisor(policy func(projectID string) *config.RestartPolicy, detached bool) *Supervisor {
+	return &Supervisor{
+		policy:   policy,
+		detached: detached,
+		now:      time.Now,
+		states:   make(map[string]*restartState),
+	}
+}
+
+// Check checks whether the tmux session of the instance is alive. A dead session puts the instance
+// in the Error status with a reason and is restarted once its backoff has passed, unless it ran out
+// of retries. An instance in the Error status whose session is alive again, e.g. because another
+// process restarted it, is set back to Running. Check returns whether the status or error reason of
+// the instance changed.
+func (s *Supervisor) Check(instance *Instance) (changed bool) {
+	key := instance.ProjectID + "/" + instance.Title
+	if !instance.Started() || instance.Paused() {
+		delete(s.states, key)
+		return false
+	}
+	state := s.states[key]
+	now := s.now()
+
+	if instance.TmuxAlive() {
+		if state != nil && now.Sub(state.restartedAt) >= restartResetAfter {
+			delete(s.states, key)
+		}
+		if instance.Status != Error {
+			return false
+		}
+		if err := s.reattach(instance); err != nil {
+			log.WarningLog.Printf("failed to reattach to the restarted session of %s: %v", instance.Title, err)
+			return false
+		}
+		log.InfoLog.Printf("session of instance %s is alive again", instance.Title)
+		instance.SetStatus(Running)
+		return true
+	}
+
+	if instance.Status != Error {
+		log.WarningLog.Printf("session of instance %s exited", instance.Title)
+		instance.SetError("session exited")
+		changed = true
+	}
+	if state == nil {
+		state = &restartState{}
+		s.states[key] = state
+	}
+	if now.Before(state.next) {
+		return changed
+	}
+
+	var policy *config.RestartPolicy
+	if s.policy != nil {
+		policy = s.policy(instance.ProjectID)
+	}
+	if !policy.Enabled() {
+		state.next = now.Add(policyRecheckInterval)
+		return changed
+	}
+	if state.restarts >= policy.MaxRetries {
+		reason := fmt.Sprintf("session exited, gave up after %d restarts", state.restarts)
+		if instance.ErrorReason != reason {
+			log.WarningLog.Printf("not restarting instance %s anymore, it was restarted %d times", instance.Title, state.restarts)
+			instance.SetError(reason)
+			changed = true
+		}
+		// The policy may be changed to allow more restarts.
+		state.next = now.Add(policyRecheckInterval)
+		return changed
+	}
+
+	state.restarts++
+	state.restartedAt = now
+	state.next = now.Add(policy.Delay(state.restarts))
+	if err := s.restart(instance); err != nil {
+		log.ErrorLog.Printf("failed to restart instance %s: %v", instance.Title, err)
+		instance.SetError(fmt.Sprintf("restart %d of %d failed: %v", state.restarts, policy.MaxRetries, err))
+		return true
+	}
+	log.InfoLog.Printf("restarted instance %s (restart %d of %d)", instance.Title, state.restarts, policy.MaxRetries)
+	return true
+}
+
+// restart starts a new tmux session for the instance in its existing worktree.
+func (s *Supervisor) restart(instance *Instance) error {
+	if err := instance.RestartTmux(); err != nil {
+		return err
+	}
+	if s.detached {
+		return instance.ReleasePTY()
+	}
+	return nil
+}
+
+// reattach replaces the PTY of the dead session with one attached to the new session.
+func (s *Supervisor) reattach(instance *Instance) error {
+	if err := instance.ReleasePTY(); err != nil {
+		log.WarningLog.Printf("failed to close the PTY of the dead session of %s: %v", instance.Title, err)
+	}
+	if s.detached {
+		return nil
+	}
+	return instance.tmuxSession.Restore()
+}
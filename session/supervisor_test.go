@@ -0,0 +1,85 @@
+package session
+
+import (
+	"claude-squad/cmd/cmd_test"
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"claude-squad/session/tmux"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervisor(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	alive := true
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			if alive {
+				return nil
+			}
+			return fmt.Errorf("session not found")
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) { return nil, nil },
+	}
+	// The worktree is missing, so restarts fail.
+	instance := &Instance{
+		Title:       "agent",
+		ProjectID:   "project",
+		Status:      Ready,
+		started:     true,
+		tmuxSession: tmux.NewTmuxSessionWithDeps("agent", "claude", nil, cmdExec),
+		gitWorktree: git.NewGitWorktreeFromStorage(t.TempDir(), filepath.Join(t.TempDir(), "missing"), "agent", "agent", ""),
+	}
+
+	var policy *config.RestartPolicy
+	now := time.Now()
+	supervisor := NewSupervisor(func(projectID string) *config.RestartPolicy { return policy }, true)
+	supervisor.now = func() time.Time { return now }
+
+	assert.False(t, supervisor.Check(instance))
+	assert.Equal(t, Ready, instance.Status)
+
+	// Without a policy the dead session is only reported.
+	alive = false
+	assert.True(t, supervisor.Check(instance))
+	assert.Equal(t, Error, instance.Status)
+	assert.Equal(t, "session exited", instance.ErrorReason)
+	assert.False(t, supervisor.Check(instance))
+
+	// The policy is looked up again after a while. The first restart happens right away, the next
+	// ones back off.
+	policy = &config.RestartPolicy{MaxRetries: 2, Backoff: 1}
+	now = now.Add(policyRecheckInterval)
+	assert.True(t, supervisor.Check(instance))
+	assert.Contains(t, instance.ErrorReason, "restart 1 of 2 failed")
+	now = now.Add(500 * time.Millisecond)
+	assert.False(t, supervisor.Check(instance))
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, supervisor.Check(instance))
+	assert.Contains(t, instance.ErrorReason, "restart 2 of 2 failed")
+	now = now.Add(2 * time.Second)
+	assert.True(t, supervisor.Check(instance))
+	assert.Equal(t, "session exited, gave up after 2 restarts", instance.ErrorReason)
+	now = now.Add(time.Hour)
+	assert.False(t, supervisor.Check(instance))
+
+	// A session restarted by someone else brings the instance back.
+	alive = true
+	assert.True(t, supervisor.Check(instance))
+	assert.Equal(t, Running, instance.Status)
+	assert.Empty(t, instance.ErrorReason)
+
+	// Paused instances are not supervised.
+	alive = false
+	instance.SetStatus(Paused)
+	assert.False(t, supervisor.Check(instance))
+	assert.Equal(t, Paused, instance.Status)
+}
@@ -0,0 +1,93 @@
+package tmux
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// followAnchorLines is the number of previously returned lines used to find where new output starts.
+const followAnchorLines = 3
+
+// HistoryFollower returns the lines a pane produces between calls to Poll. Only lines above the
+// cursor are considered, since the line the cursor is on and everything below it may still change.
+type HistoryFollower struct {
+	session *TmuxSession
+	// anchor holds the last lines returned by Poll
+	anchor []string
+	// hint is the number of lines seen in the previous poll, where new output is expected to start
+	hint int
+}
+
+// NewHistoryFollower creates a follower. The first call to Poll returns the whole history.
+func (t *TmuxSession) NewHistoryFollower() *HistoryFollower {
+	return &HistoryFollower{session: t}
+}
+
+// Poll returns the lines added since the previous call.
+func (f *HistoryFollower) Poll() ([]string, error) {
+	cursorY, err := f.session.cursorY()
+	if err != nil {
+		return nil, err
+	}
+	// -E is relative to the first visible line, so this captures the history and the visible lines
+	// up to the cursor. Wrapped lines are joined, so the last line is the whole line the cursor is
+	// on, which is dropped.
+	content, err := f.session.CapturePaneContentWithOptions("-", strconv.Itoa(cursorY))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	lines = lines[:len(lines)-1]
+
+	start := findNewLines(f.anchor, f.hint, lines)
+	f.hint = len(lines)
+	if len(lines) > 0 {
+		f.anchor = append([]string(nil), lines[max(0, len(lines)-followAnchorLines):]...)
+	}
+	return lines[start:], nil
+}
+
+// findNewLines returns the index in lines where the lines after anchor start. The anchor is
+// searched from hint backwards, since lines are only added at the end and the oldest ones are
+// dropped once the history limit is reached. If the anchor is gone, the lines after hint are
+// assumed to be new.
+func findNewLines(anchor []string, hint int, lines []string) int {
+	if len(anchor) == 0 {
+		return 0
+	}
+	hint = min(hint, len(lines))
+	for end := hint; end >= len(anchor); end-- {
+		if equalLines(lines[end-len(anchor):end], anchor) {
+			return end
+		}
+	}
+	return hint
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// cursorY returns the row of the cursor relative to the first visible line of the pane.
+func (t *TmuxSession) cursorY() (int, error) {
+	cmd := exec.Command("tmux", "display-message", "-p", "-t", t.sanitizedName, "#{cursor_y}")
+	output, err := t.cmdExec.Output(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cursor position: %v", err)
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse cursor position %q: %v", output, err)
+	}
+	return y, nil
+}
@@ -0,0 +1,63 @@
+package tmux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindNewLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		anchor   []string
+		hint     int
+		lines    []string
+		expected int
+	}{
+		{
+			name:     "first poll returns everything",
+			lines:    []string{"a", "b"},
+			expected: 0,
+		},
+		{
+			name:     "lines appended",
+			anchor:   []string{"a", "b", "c"},
+			hint:     3,
+			lines:    []string{"a", "b", "c", "d", "e"},
+			expected: 3,
+		},
+		{
+			name:     "nothing new",
+			anchor:   []string{"a", "b", "c"},
+			hint:     3,
+			lines:    []string{"a", "b", "c"},
+			expected: 3,
+		},
+		{
+			name:     "oldest lines dropped by the history limit",
+			anchor:   []string{"c", "d", "e"},
+			hint:     5,
+			lines:    []string{"c", "d", "e", "f", "g"},
+			expected: 3,
+		},
+		{
+			name:     "repeated output prefers the expected position",
+			anchor:   []string{"x", "y", "x"},
+			hint:     6,
+			lines:    []string{"x", "y", "x", "x", "y", "x", "x", "y", "x"},
+			expected: 6,
+		},
+		{
+			name:     "anchor gone falls back to the previous line count",
+			anchor:   []string{"p", "q", "r"},
+			hint:     2,
+			lines:    []string{"a", "b", "c", "d"},
+			expected: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, findNewLines(tt.anchor, tt.hint, tt.lines))
+		})
+	}
+}
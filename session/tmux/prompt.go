@@ -0,0 +1,53 @@
+package tmux
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxPromptLines bounds how far above its answer options an approval prompt is looked for.
+const maxPromptLines = 30
+
+// Prompt returns the approval prompt found by the last call to HasUpdated, or "" if there was none.
+func (t *TmuxSession) Prompt() string {
+	if t.monitor == nil {
+		return ""
+	}
+	return t.monitor.prompt
+}
+
+// extractPrompt returns the approval prompt in the pane content as plain text: the lines from the
+// start of the box or section the prompt is drawn in down to the last line containing marker.
+// Earlier output stays out, so that approval rules don't match commands which already ran.
+func extractPrompt(content, marker string) string {
+	lines := strings.Split(ansi.Strip(content), "\n")
+	end := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], marker) {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return ""
+	}
+	start := end
+	for start > 0 && end-start < maxPromptLines {
+		if isPromptBoundary(lines[start-1]) {
+			break
+		}
+		start--
+	}
+	return strings.Join(lines[start:end+1], "\n")
+}
+
+// isPromptBoundary reports whether line is the top border of a box or a horizontal rule, which
+// claude and gemini draw above their prompts.
+func isPromptBoundary(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "╭") || strings.HasPrefix(trimmed, "┌") {
+		return true
+	}
+	return len(trimmed) > 0 && strings.Trim(trimmed, "─━-") == ""
+}
@@ -0,0 +1,55 @@
+package tmux
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractPrompt(t *testing.T) {
+	const marker = "No, and tell Claude what to do differently"
+
+	t.Run("stops at the box border", func(t *testing.T) {
+		content := "⏺ Bash(rm -rf old)\n  ⎿ done\n" +
+			"╭──────────────────────╮\n" +
+			"│ Bash command         │\n" +
+			"│   go test ./...      │\n" +
+			"│ ❯ 1. Yes             │\n" +
+			"│   3. " + marker + " │\n" +
+			"╰──────────────────────╯\n"
+		prompt := extractPrompt(content, marker)
+		assert.Contains(t, prompt, "go test ./...")
+		assert.NotContains(t, prompt, "rm -rf")
+		assert.NotContains(t, prompt, "╰")
+	})
+
+	t.Run("stops at a horizontal rule", func(t *testing.T) {
+		content := "curl example.com\n" +
+			"────────────────────────\n" +
+			" Bash command\n" +
+			"   ls\n" +
+			" 3. " + marker + "\n"
+		prompt := extractPrompt(content, marker)
+		assert.Equal(t, " Bash command\n   ls\n 3. "+marker, prompt)
+	})
+
+	t.Run("is bounded without a border", func(t *testing.T) {
+		content := ""
+		for i := 0; i < 2*maxPromptLines; i++ {
+			content += "line\n"
+		}
+		content += marker
+		lines := extractPrompt(content, marker)
+		assert.Equal(t, maxPromptLines+1, len(strings.Split(lines, "\n")))
+	})
+
+	t.Run("strips escape sequences", func(t *testing.T) {
+		content := "\x1b[1mBash command\x1b[0m\n   \x1b[31mrm\x1b[0m -rf build\n 3. " + marker
+		assert.Equal(t, "Bash command\n   rm -rf build\n 3. "+marker, extractPrompt(content, marker))
+	})
+
+	t.Run("no prompt", func(t *testing.T) {
+		assert.Empty(t, extractPrompt("nothing to see", marker))
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -61,6 +62,11 @@ const TmuxPrefix = "claudesquad_"
 
 var whiteSpaceRegex = regexp.MustCompile(`\s+`)
 
+// SessionName returns the name of the tmux session used for an instance with the given title.
+func SessionName(title string) string {
+	return toClaudeSquadTmuxName(title)
+}
+
 func toClaudeSquadTmuxName(str string) string {
 	str = whiteSpaceRegex.ReplaceAllString(str, "")
 	str = strings.ReplaceAll(str, ".", "_") // tmux replaces all . with _
@@ -229,6 +235,8 @@ func (t *TmuxSession) Restore() error {
 type statusMonitor struct {
 	// Store hashes to save memory.
 	prevOutputHash []byte
+	// prompt is the approval prompt found by the last call to HasUpdated.
+	prompt string
 }
 
 func newStatusMonitor() *statusMonitor {
@@ -245,6 +253,12 @@ func (m *statusMonitor) hash(s string) []byte {
 
 // TapEnter sends an enter keystroke to the tmux pane.
 func (t *TmuxSession) TapEnter() error {
+	if t.ptmx == nil {
+		if err := t.sendKeysToServer("Enter"); err != nil {
+			return fmt.Errorf("error sending enter keystroke to tmux: %w", err)
+		}
+		return nil
+	}
 	_, err := t.ptmx.Write([]byte{0x0D})
 	if err != nil {
 		return fmt.Errorf("error sending enter keystroke to PTY: %w", err)
@@ -261,11 +275,26 @@ func (t *TmuxSession) TapDAndEnter() error {
 	return nil
 }
 
+// SendKeys types keys into the tmux pane. If this process has no PTY attached to the session (it was
+// neither started nor restored here), the keys are delivered through the tmux server instead. That
+// doesn't interfere with a PTY owned by another process.
 func (t *TmuxSession) SendKeys(keys string) error {
+	if t.ptmx == nil {
+		return t.sendKeysToServer("-l", "--", keys)
+	}
 	_, err := t.ptmx.Write([]byte(keys))
 	return err
 }
 
+// sendKeysToServer runs tmux send-keys against the session with the given arguments.
+func (t *TmuxSession) sendKeysToServer(args ...string) error {
+	cmd := exec.Command("tmux", append([]string{"send-keys", "-t", t.sanitizedName}, args...)...)
+	if err := t.cmdExec.Run(cmd); err != nil {
+		return fmt.Errorf("error running %s: %w", cmd.String(), err)
+	}
+	return nil
+}
+
 // HasUpdated checks if the tmux pane content has changed since the last tick. It also returns true if
 // the tmux pane has a prompt for aider or claude code.
 func (t *TmuxSession) HasUpdated() (updated bool, hasPrompt bool) {
@@ -276,14 +305,24 @@ func (t *TmuxSession) HasUpdated() (updated bool, hasPrompt bool) {
 	}
 
 	// Only set hasPrompt for claude and aider. Use these strings to check for a prompt.
+	var marker string
 	if t.program == ProgramClaude {
-		hasPrompt = strings.Contains(content, "No, and tell Claude what to do differently")
+		marker = "No, and tell Claude what to do differently"
 	} else if strings.HasPrefix(t.program, ProgramAider) {
-		hasPrompt = strings.Contains(content, "(Y)es/(N)o/(D)on't ask again")
+		marker = "(Y)es/(N)o/(D)on't ask again"
 	} else if strings.HasPrefix(t.program, ProgramGemini) {
-		hasPrompt = strings.Contains(content, "Yes, allow once")
+		marker = "Yes, allow once"
 	}
+	hasPrompt = marker != "" && strings.Contains(content, marker)
 
+	// Sessions which were never restored in this process have no monitor yet.
+	if t.monitor == nil {
+		t.monitor = newStatusMonitor()
+	}
+	t.monitor.prompt = ""
+	if hasPrompt {
+		t.monitor.prompt = extractPrompt(content, marker)
+	}
 	if !bytes.Equal(t.monitor.hash(content), t.monitor.prevOutputHash) {
 		t.monitor.prevOutputHash = t.monitor.hash(content)
 		return true, hasPrompt
@@ -292,6 +331,12 @@ func (t *TmuxSession) HasUpdated() (updated bool, hasPrompt bool) {
 }
 
 func (t *TmuxSession) Attach() (chan struct{}, error) {
+	// Sessions loaded without a PTY need one before we can attach.
+	if t.ptmx == nil {
+		if err := t.Restore(); err != nil {
+			return nil, err
+		}
+	}
 	t.attachCh = make(chan struct{})
 
 	t.wg = &sync.WaitGroup{}
@@ -445,6 +490,20 @@ func (t *TmuxSession) Detach() {
 	t.wg.Wait()
 }
 
+// ReleasePTY closes the PTY attached to the session without terminating the session. Keys are sent
+// through the tmux server afterwards.
+func (t *TmuxSession) ReleasePTY() error {
+	if t.ptmx == nil {
+		return nil
+	}
+	err := t.ptmx.Close()
+	t.ptmx = nil
+	if err != nil {
+		return fmt.Errorf("error closing PTY: %w", err)
+	}
+	return nil
+}
+
 // Close terminates the tmux session and cleans up resources
 func (t *TmuxSession) Close() error {
 	var errs []error
@@ -478,6 +537,10 @@ func (t *TmuxSession) Close() error {
 // SetDetachedSize set the width and height of the session while detached. This makes the
 // tmux output conform to the specified shape.
 func (t *TmuxSession) SetDetachedSize(width, height int) error {
+	// Sessions without a PTY, e.g. of instances the daemon runs, keep their size.
+	if t.ptmx == nil {
+		return nil
+	}
 	return t.updateWindowSize(width, height)
 }
 
@@ -520,31 +583,63 @@ func (t *TmuxSession) CapturePaneContentWithOptions(start, end string) (string,
 	return string(output), nil
 }
 
-// CleanupSessions kills all tmux sessions that start with "session-"
-func CleanupSessions(cmdExec cmd.Executor) error {
-	// First try to list sessions
-	cmd := exec.Command("tmux", "ls")
+// SessionInfo describes a tmux session created by claude-squad.
+type SessionInfo struct {
+	// Name is the full tmux session name, including TmuxPrefix.
+	Name string
+	// Created is when the session was started.
+	Created time.Time
+}
+
+// ListSessions returns all tmux sessions that start with TmuxPrefix. It returns no sessions if the
+// tmux server is not running.
+func ListSessions(cmdExec cmd.Executor) ([]SessionInfo, error) {
+	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}:#{session_created}")
 	output, err := cmdExec.Output(cmd)
 
 	// If there's an error and it's because no server is running, that's fine
 	// Exit code 1 typically means no sessions exist
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-			return nil // No sessions to clean up
+			return nil, nil
 		}
-		return fmt.Errorf("failed to list tmux sessions: %v", err)
+		return nil, fmt.Errorf("failed to list tmux sessions: %v", err)
 	}
 
-	re := regexp.MustCompile(fmt.Sprintf(`%s.*:`, TmuxPrefix))
-	matches := re.FindAllString(string(output), -1)
-	for i, match := range matches {
-		matches[i] = match[:strings.Index(match, ":")]
+	var sessions []SessionInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		idx := strings.LastIndex(line, ":")
+		if idx < 0 || !strings.HasPrefix(line, TmuxPrefix) {
+			continue
+		}
+		info := SessionInfo{Name: line[:idx]}
+		if created, err := strconv.ParseInt(line[idx+1:], 10, 64); err == nil {
+			info.Created = time.Unix(created, 0)
+		}
+		sessions = append(sessions, info)
+	}
+	return sessions, nil
+}
+
+// KillSession kills the tmux session with the given full name.
+func KillSession(cmdExec cmd.Executor, name string) error {
+	if err := cmdExec.Run(exec.Command("tmux", "kill-session", "-t", name)); err != nil {
+		return fmt.Errorf("failed to kill tmux session %s: %v", name, err)
+	}
+	return nil
+}
+
+// CleanupSessions kills all tmux sessions that start with TmuxPrefix
+func CleanupSessions(cmdExec cmd.Executor) error {
+	sessions, err := ListSessions(cmdExec)
+	if err != nil {
+		return err
 	}
 
-	for _, match := range matches {
-		log.InfoLog.Printf("cleaning up session: %s", match)
-		if err := cmdExec.Run(exec.Command("tmux", "kill-session", "-t", match)); err != nil {
-			return fmt.Errorf("failed to kill tmux session %s: %v", match, err)
+	for _, session := range sessions {
+		log.InfoLog.Printf("cleaning up session: %s", session.Name)
+		if err := KillSession(cmdExec, session.Name); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -2,6 +2,7 @@ package tmux
 
 import (
 	cmd2 "claude-squad/cmd"
+	"claude-squad/log"
 	"fmt"
 	"math/rand"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"claude-squad/cmd/cmd_test"
 
@@ -41,6 +43,16 @@ func NewMockPtyFactory(t *testing.T) *MockPtyFactory {
 	}
 }
 
+// TestMain runs before all tests to set up the test environment
+func TestMain(m *testing.M) {
+	// Initialize the logger before any tests run
+	log.Initialize(false)
+	defer log.Close()
+
+	exitCode := m.Run()
+	os.Exit(exitCode)
+}
+
 func TestSanitizeName(t *testing.T) {
 	session := NewTmuxSession("asdf", "program")
 	require.Equal(t, TmuxPrefix+"asdf", session.sanitizedName)
@@ -86,3 +98,31 @@ func TestStartTmuxSession(t *testing.T) {
 	_, err = ptyFactory.files[1].Stat()
 	require.NoError(t, err)
 }
+
+func TestListSessions(t *testing.T) {
+	cmdExec := cmd_test.MockCmdExec{
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			require.Equal(t, "tmux list-sessions -F #{session_name}:#{session_created}", cmd2.ToString(cmd))
+			return []byte("claudesquad_foo:1700000000\nother:1700000001\nclaudesquad_bar:1700000002\n"), nil
+		},
+	}
+
+	sessions, err := ListSessions(cmdExec)
+	require.NoError(t, err)
+	require.Equal(t, []SessionInfo{
+		{Name: "claudesquad_foo", Created: time.Unix(1700000000, 0)},
+		{Name: "claudesquad_bar", Created: time.Unix(1700000002, 0)},
+	}, sessions)
+}
+
+func TestListSessionsNoServer(t *testing.T) {
+	cmdExec := cmd_test.MockCmdExec{
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return exec.Command("sh", "-c", "exit 1").Output()
+		},
+	}
+
+	sessions, err := ListSessions(cmdExec)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
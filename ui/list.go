@@ -14,6 +14,7 @@ import (
 const readyIcon = "● "
 const pausedIcon = "⏸ "
 const errorIcon = "✗ "
+const approvalIcon = "? "
 
 var readyStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#51bd73", Dark: "#51bd73"})
@@ -30,6 +31,9 @@ var pausedStyle = lipgloss.NewStyle().
 var errorStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#FF0000", Dark: "#FF0000"})
 
+var approvalStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#d18616", Dark: "#f0a020"})
+
 var titleStyle = lipgloss.NewStyle().
 	Padding(1, 1, 0, 1).
 	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
@@ -144,6 +148,10 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		join = errorStyle.Render(errorIcon)
 	default:
 	}
+	// A prompt the approval policy denied waits for the user, whatever the status.
+	if i.NeedsApproval {
+		join = approvalStyle.Render(approvalIcon)
+	}
 
 	// Cut the title if it's too long
 	titleText := i.DisplayName
@@ -202,6 +210,10 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 			branch += fmt.Sprintf(" (%s)", repoName)
 		}
 	}
+	// Instances created by a schedule name it, so they don't look like somebody forgot them.
+	if i.ScheduledBy != "" {
+		branch += fmt.Sprintf(" [%s]", i.ScheduledBy)
+	}
 	// Don't show branch if there's no space for it. Or show ellipsis if it's too long.
 	if remainingWidth < 0 {
 		branch = ""
@@ -292,21 +304,49 @@ func (l *List) Kill() {
 		log.ErrorLog.Printf("could not kill instance: %v", err)
 	}
 
-	// If you delete the last one in the list, select the previous one.
-	if l.selectedIdx == len(l.items)-1 {
-		defer l.Up()
+	l.removeAt(l.selectedIdx)
+}
+
+// Remove removes an instance from the list without killing it, e.g. because another process killed
+// it. Noop if the instance is not in the list.
+func (l *List) Remove(instance *session.Instance) {
+	for idx, item := range l.items {
+		if item == instance {
+			l.removeAt(idx)
+			return
+		}
 	}
+}
 
-	// Unregister the reponame.
-	repoName, err := targetInstance.RepoName()
-	if err != nil {
-		log.ErrorLog.Printf("could not get repo name: %v", err)
-	} else {
-		l.rmRepo(repoName)
+// Replace puts an instance in the place of another one, e.g. a new copy after another process paused
+// or resumed it. Noop if old is not in the list.
+func (l *List) Replace(old, instance *session.Instance) {
+	for idx, item := range l.items {
+		if item == old {
+			l.items[idx] = instance
+			return
+		}
 	}
+}
 
-	// Since there's items after this, the selectedIdx can stay the same.
-	l.items = append(l.items[:l.selectedIdx], l.items[l.selectedIdx+1:]...)
+// removeAt removes the item at idx and keeps the selection on the same instance if possible.
+func (l *List) removeAt(idx int) {
+	// Unregister the reponame. It is only registered once the instance is started.
+	if l.items[idx].Started() {
+		repoName, err := l.items[idx].RepoName()
+		if err != nil {
+			log.ErrorLog.Printf("could not get repo name: %v", err)
+		} else {
+			l.rmRepo(repoName)
+		}
+	}
+
+	l.items = append(l.items[:idx], l.items[idx+1:]...)
+	// Items after the selected one move up, and if you delete the last one in the list, the previous
+	// one is selected.
+	if idx < l.selectedIdx || l.selectedIdx >= len(l.items) {
+		l.Up()
+	}
 }
 
 func (l *List) Attach() (chan struct{}, error) {
@@ -114,8 +114,12 @@ func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
 		))
 		return nil
 	case instance.Status == session.Error:
+		message := "Session encountered an error."
+		if instance.ErrorReason != "" {
+			message = fmt.Sprintf("Session encountered an error: %s.", instance.ErrorReason)
+		}
 		p.setFallbackState(lipgloss.JoinVertical(lipgloss.Center,
-			"Session encountered an error.",
+			message,
 			"",
 			lipgloss.NewStyle().
 				Foreground(lipgloss.AdaptiveColor{
@@ -207,7 +211,7 @@ func (p *PreviewPane) attemptTmuxRecovery(instance *session.Instance, originalEr
 	// Tmux session is dead, attempt to restart it
 	if err := instance.RestartTmux(); err != nil {
 		// Failed to restart, mark instance as Error state
-		instance.SetStatus(session.Error)
+		instance.SetError(fmt.Sprintf("restart failed: %v", err))
 		return fmt.Errorf("tmux session died and failed to restart: %w (original error: %v)", err, originalErr)
 	}
 
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// waitPollInterval is how often the instance is checked, the same interval the UI uses.
+const waitPollInterval = 500 * time.Millisecond
+
+// waitConditions are the states wait can block on.
+var waitConditions = []string{"ready", "paused", "prompt"}
+
+var (
+	waitUntilFlag   string
+	waitTimeoutFlag time.Duration
+	waitSettleFlag  time.Duration
+
+	waitCmd = &cobra.Command{
+		Use:   "wait <title>",
+		Short: "Block until an instance is ready, paused or shows a prompt",
+		Long: "Block until an instance reaches a state and exit 0, or exit 1 if the timeout expires first.\n\n" +
+			"  ready   the program's output stopped changing, e.g. the agent finished and waits for input\n" +
+			"  paused  the instance was paused\n" +
+			"  prompt  the program asks for permission (claude, aider and gemini only)\n\n" +
+			"A program which is busy without printing anything looks ready, use --settle to require the " +
+			"output to stay unchanged for longer. Like in the UI, prompts are accepted automatically if the instance has autoyes enabled and the " +
+			"approval policy allows them.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			until := strings.ToLower(waitUntilFlag)
+			valid := false
+			for _, condition := range waitConditions {
+				valid = valid || until == condition
+			}
+			if !valid {
+				return fmt.Errorf("invalid --until %q, must be one of %s", waitUntilFlag, strings.Join(waitConditions, ", "))
+			}
+			if waitTimeoutFlag < 0 || waitSettleFlag < 0 {
+				return fmt.Errorf("--timeout and --settle cannot be negative")
+			}
+
+			projectManager, err := currentProjectManager()
+			if err != nil {
+				return err
+			}
+			// The instance is kept across polls since it remembers the pane content of the last poll.
+			instance, err := projectManager.GetInstanceDetached(args[0])
+			if err != nil {
+				return err
+			}
+
+			approvals := config.LoadConfig().ApprovalMatcher()
+			session.SetAuditSource("wait")
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if waitTimeoutFlag > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, waitTimeoutFlag)
+				defer cancel()
+			}
+
+			var readySince time.Time
+			ticker := time.NewTicker(waitPollInterval)
+			defer ticker.Stop()
+			for {
+				// The instance may be paused or killed by another process while waiting.
+				stored, err := projectManager.GetInstanceDetached(instance.Title)
+				if err != nil {
+					return err
+				}
+				switch {
+				case stored.Paused() && until == "paused":
+					fmt.Printf("'%s' is paused\n", instance.Title)
+					return nil
+				case stored.Paused():
+					return fmt.Errorf("instance '%s' was paused before it was %s", instance.Title, until)
+				case until == "paused":
+				case !instance.TmuxAlive():
+					return fmt.Errorf("tmux session of instance '%s' is not running", instance.Title)
+				default:
+					// Prompts are left to the owner of the project, if any.
+					var hasPrompt bool
+					if holder, err := projectManager.LeaseHolder(); err != nil || holder != nil {
+						hasPrompt = instance.UpdateStatus(approvals)
+					} else {
+						hasPrompt = instance.RefreshStatus(approvals)
+					}
+					if until == "prompt" && hasPrompt {
+						fmt.Printf("'%s' shows a prompt\n", instance.Title)
+						return nil
+					}
+					if instance.Status != session.Ready {
+						readySince = time.Time{}
+					} else if readySince.IsZero() {
+						readySince = time.Now()
+					}
+					if until == "ready" && !readySince.IsZero() && time.Since(readySince) >= waitSettleFlag {
+						fmt.Printf("'%s' is ready\n", instance.Title)
+						return nil
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+						return fmt.Errorf("timed out after %s waiting for '%s' to be %s", waitTimeoutFlag, instance.Title, until)
+					}
+					return fmt.Errorf("interrupted")
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+)
+
+func init() {
+	waitCmd.Flags().StringVar(&waitUntilFlag, "until", "ready", "State to wait for: "+strings.Join(waitConditions, ", "))
+	waitCmd.Flags().DurationVar(&waitSettleFlag, "settle", 0, "How long the output has to stay unchanged to count as ready")
+	waitCmd.Flags().DurationVar(&waitTimeoutFlag, "timeout", 0, "Give up after this long, e.g. 30m (0 waits forever)")
+
+	rootCmd.AddCommand(waitCmd)
+}